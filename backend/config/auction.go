@@ -0,0 +1,69 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// BuyNowCutoff returns the share of the buy-now price at which bidding withdraws
+// the buy-now option. It is read from BUY_NOW_CUTOFF and defaults to 0.5.
+func BuyNowCutoff() float64 {
+	cutoff, err := strconv.ParseFloat(os.Getenv("BUY_NOW_CUTOFF"), 64)
+	if err != nil || cutoff <= 0 || cutoff > 1 {
+		return 0.5
+	}
+	return cutoff
+}
+
+// EndingSoonLead returns how long before an auction ends its watchers are
+// reminded. It is read in minutes from ENDING_SOON_MINUTES and defaults to an hour.
+func EndingSoonLead() time.Duration {
+	minutes, err := strconv.Atoi(os.Getenv("ENDING_SOON_MINUTES"))
+	if err != nil || minutes <= 0 {
+		return time.Hour
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// ReviewEditWindow returns how long after it is written a review can still be
+// edited. It is read in days from REVIEW_EDIT_DAYS and defaults to 30 days.
+func ReviewEditWindow() time.Duration {
+	days, err := strconv.Atoi(os.Getenv("REVIEW_EDIT_DAYS"))
+	if err != nil || days <= 0 {
+		days = 30
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// PaymentWindow returns how long a buyer has to pay after winning. It is read
+// in hours from PAYMENT_HOURS and defaults to 72 hours.
+func PaymentWindow() time.Duration {
+	hours, err := strconv.Atoi(os.Getenv("PAYMENT_HOURS"))
+	if err != nil || hours <= 0 {
+		hours = 72
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// DeliveryConfirmWindow returns how long after shipping a delivery is
+// confirmed automatically if the buyer neither confirms nor disputes it. It is
+// read in days from DELIVERY_CONFIRM_DAYS and defaults to 14 days.
+func DeliveryConfirmWindow() time.Duration {
+	days, err := strconv.Atoi(os.Getenv("DELIVERY_CONFIRM_DAYS"))
+	if err != nil || days <= 0 {
+		days = 14
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// EscrowThreshold returns the smallest payment that is held in escrow until
+// delivery; smaller payments go straight to the seller. It is read from
+// ESCROW_THRESHOLD and defaults to 500. A threshold of 0 escrows every payment.
+func EscrowThreshold() float64 {
+	threshold, err := strconv.ParseFloat(os.Getenv("ESCROW_THRESHOLD"), 64)
+	if err != nil || threshold < 0 {
+		return 500
+	}
+	return threshold
+}
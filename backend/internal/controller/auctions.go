@@ -1,9 +1,12 @@
 package controller
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -34,33 +37,135 @@ func CreateAuctionHandler(c *gin.Context) {
 		return
 	}
 
-	itemID, err := db.CreateItem(
-		c,
-		userID,
-		combinedRequest.Title,
-		combinedRequest.Description,
-		combinedRequest.StartingBid,
-		combinedRequest.ImagePath,
-	)
+	increments, err := incrementSchedule(combinedRequest)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create item"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	auctionID, err := db.CreateAuction(
-		c,
-		itemID,
-		combinedRequest.StartTime,
-		combinedRequest.EndTime,
-	)
+	if combinedRequest.ReservePrice != 0 && combinedRequest.ReservePrice < combinedRequest.StartingBid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Reserve price cannot be below the starting bid"})
+		return
+	}
+
+	auctionType := combinedRequest.AuctionType
+	switch auctionType {
+	case "":
+		auctionType = db.AuctionTypeEnglish
+	case db.AuctionTypeEnglish, db.AuctionTypeSealedFirstPrice, db.AuctionTypeSealedSecondPrice, db.AuctionTypeDutch:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid auction type"})
+		return
+	}
+
+	if db.IsSealed(auctionType) && (combinedRequest.BuyNowPrice != 0 || combinedRequest.SoftClose.WindowMinutes != 0) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Sealed-bid auctions cannot offer buy now or soft close"})
+		return
+	}
+
+	quantity := combinedRequest.Quantity
+	if quantity == 0 {
+		quantity = 1
+	}
+	if quantity < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Quantity must be positive"})
+		return
+	}
+	if quantity > 1 && (auctionType != db.AuctionTypeEnglish || combinedRequest.BuyNowPrice != 0) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Multi-quantity auctions must be English auctions without buy now"})
+		return
+	}
+
+	if combinedRequest.AutoRelist < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Automatic relisting count cannot be negative"})
+		return
+	}
+
+	tags, ok := normalizeTags(combinedRequest.Tags)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "An item can have at most 10 tags of up to 50 characters each"})
+		return
+	}
+
+	if combinedRequest.CategoryID != 0 {
+		exists, err := db.CategoryExists(c, combinedRequest.CategoryID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check category"})
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Category not found"})
+			return
+		}
+	}
+
+	if lotItems := combinedRequest.LotItems; len(lotItems) > 0 {
+		if len(lotItems) < 2 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A lot must contain at least two items"})
+			return
+		}
+		if quantity > 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Lots are sold as a single unit"})
+			return
+		}
+		for _, lotItem := range lotItems {
+			if lotItem.Title == "" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Every item in a lot needs a title"})
+				return
+			}
+		}
+	}
+
+	dutch := combinedRequest.Dutch
+	if auctionType == db.AuctionTypeDutch {
+		if combinedRequest.ReservePrice != 0 || combinedRequest.BuyNowPrice != 0 || combinedRequest.SoftClose.WindowMinutes != 0 || len(increments) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Dutch auctions cannot have a reserve price, buy now, soft close or bid increments"})
+			return
+		}
+		if dutch.FloorPrice <= 0 || dutch.FloorPrice >= combinedRequest.StartingBid || dutch.PriceStep <= 0 || dutch.StepMinutes <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Dutch auctions need a floor price below the starting bid, a positive price step and a positive step interval"})
+			return
+		}
+	} else if dutch != (schema.DutchSettings{}) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A price schedule can only be set on Dutch auctions"})
+		return
+	}
+
+	softClose := combinedRequest.SoftClose
+	if softClose.WindowMinutes < 0 || softClose.ExtensionMinutes < 0 || softClose.MaxExtensions < 0 ||
+		(softClose.WindowMinutes > 0) != (softClose.ExtensionMinutes > 0) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Soft close needs both a positive window and a positive extension"})
+		return
+	}
+
+	if combinedRequest.BuyNowPrice != 0 && (combinedRequest.BuyNowPrice <= combinedRequest.StartingBid || combinedRequest.BuyNowPrice < combinedRequest.ReservePrice) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Buy now price must be above the starting bid and not below the reserve price"})
+		return
+	}
+
+	combinedRequest.AuctionType = auctionType
+	combinedRequest.Quantity = quantity
+	combinedRequest.Tags = tags
+
+	itemID, auctionID, err := db.CreateAuction(c, userID, combinedRequest, increments)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create auction"})
 		return
 	}
 
+	if combinedRequest.Draft {
+		c.JSON(http.StatusCreated, gin.H{
+			"auction_id": auctionID,
+			"item_id":    itemID,
+			"message":    "Draft auction saved successfully",
+		})
+		return
+	}
+
+	announceListing(c, auctionID)
+
 	if wsManager != nil {
-		auction, _ := db.GetAuctionByID(c, auctionID, userID)
-		wsManager.BroadcastNewAuction(auction)
+		wsManager.BroadcastNewAuction(publicAuction(c, auctionID))
 	}
 
 	c.JSON(http.StatusCreated, gin.H{
@@ -70,17 +175,125 @@ func CreateAuctionHandler(c *gin.Context) {
 	})
 }
 
-// GetAuctionsHandler retrieves a list of all active auctions
+// incrementSchedule validates the seller's increment settings. A fixed increment
+// becomes a single tier; an empty result means the site default applies.
+func incrementSchedule(request schema.ItemAuctionRequest) ([]schema.BidIncrementTier, error) {
+	if len(request.IncrementTiers) == 0 {
+		if request.BidIncrement < 0 {
+			return nil, fmt.Errorf("Bid increment must be positive")
+		}
+		if request.BidIncrement == 0 {
+			return nil, nil
+		}
+		return []schema.BidIncrementTier{{MinPrice: 0, Increment: request.BidIncrement}}, nil
+	}
+
+	tiers := append([]schema.BidIncrementTier(nil), request.IncrementTiers...)
+	sort.Slice(tiers, func(i, j int) bool { return tiers[i].MinPrice < tiers[j].MinPrice })
+
+	if tiers[0].MinPrice != 0 {
+		return nil, fmt.Errorf("Increment tiers must start at a price of 0")
+	}
+	for i, tier := range tiers {
+		if tier.Increment <= 0 {
+			return nil, fmt.Errorf("Bid increment must be positive")
+		}
+		if i > 0 && tier.MinPrice == tiers[i-1].MinPrice {
+			return nil, fmt.Errorf("Increment tiers must have distinct prices")
+		}
+	}
+
+	return tiers, nil
+}
+
+// GetAuctionsHandler retrieves a list of all active auctions, optionally
+// narrowed to a category (including its subcategories) and a tag
 func GetAuctionsHandler(c *gin.Context) {
-	auctions, err := db.GetAuctions(c)
+	userID, err := helpers.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	var filter schema.AuctionFilter
+	if category := c.Query("category"); category != "" {
+		categoryID, err := strconv.Atoi(category)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+			return
+		}
+		filter.CategoryID = categoryID
+	}
+	filter.Tag = strings.ToLower(strings.TrimSpace(c.Query("tag")))
+
+	page, ok := pageRequest(c)
+	if !ok {
+		return
+	}
+
+	auctions, err := db.GetAuctions(c, userID, filter, page)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve auctions"})
+		respondPageError(c, err, "Failed to retrieve auctions")
 		return
 	}
 
 	c.JSON(http.StatusOK, auctions)
 }
 
+// SearchAuctionsHandler runs a full-text search over auction titles,
+// descriptions and seller names, optionally filtered by status, current price
+// and end time
+func SearchAuctionsHandler(c *gin.Context) {
+	userID, err := helpers.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	search := schema.AuctionSearch{Query: strings.TrimSpace(c.Query("q"))}
+	if search.Query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Search query is required"})
+		return
+	}
+
+	page, ok := pageRequest(c)
+	if !ok {
+		return
+	}
+
+	if minPrice := c.Query("min_price"); minPrice != "" {
+		if search.MinPrice, err = strconv.ParseFloat(minPrice, 64); err != nil || search.MinPrice < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid minimum price"})
+			return
+		}
+	}
+	if maxPrice := c.Query("max_price"); maxPrice != "" {
+		if search.MaxPrice, err = strconv.ParseFloat(maxPrice, 64); err != nil || search.MaxPrice < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid maximum price"})
+			return
+		}
+	}
+	if search.MaxPrice != 0 && search.MinPrice > search.MaxPrice {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Minimum price cannot be above the maximum price"})
+		return
+	}
+
+	if endingBefore := c.Query("ending_before"); endingBefore != "" {
+		if search.EndingBefore, err = time.Parse(time.RFC3339, endingBefore); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "ending_before must be an RFC 3339 time"})
+			return
+		}
+	}
+
+	results, err := db.SearchAuctions(c, userID, search, page)
+	if err != nil {
+		respondPageError(c, err, "Failed to search auctions")
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
 // GetAuctionHandler retrieves details of a specific auction
 func GetAuctionHandler(c *gin.Context) {
 	userID, err := helpers.GetUserID(c)
@@ -101,11 +314,16 @@ func GetAuctionHandler(c *gin.Context) {
 		return
 	}
 
+	if auction.Status == "draft" && auction.SellerID != userID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Auction not found"})
+		return
+	}
+
 	c.JSON(http.StatusOK, auction)
 }
 
-// PlaceBidHandler handles placing a bid on an auction
-func PlaceBidHandler(c *gin.Context) {
+// UpdateAuctionHandler lets the seller edit an auction's item and times until the first bid
+func UpdateAuctionHandler(c *gin.Context) {
 	userID, err := helpers.GetUserID(c)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
@@ -118,8 +336,8 @@ func PlaceBidHandler(c *gin.Context) {
 		return
 	}
 
-	var bidRequest schema.BidCreate
-	if err := c.BindJSON(&bidRequest); err != nil {
+	var request schema.AuctionUpdateRequest
+	if err := c.BindJSON(&request); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
 		return
 	}
@@ -130,108 +348,430 @@ func PlaceBidHandler(c *gin.Context) {
 		return
 	}
 
-	if bidRequest.Amount <= auction.CurrentHighestBid {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Bid amount must be higher than current highest bid"})
+	if auction.SellerID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the seller can edit this auction"})
 		return
 	}
 
-	currentHighestBidder, err := db.GetCurrentHighestBidder(c, auctionID)
+	if (request.Title != nil && *request.Title == "") || (request.Description != nil && *request.Description == "") ||
+		(request.ImagePath != nil && *request.ImagePath == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Title, description and image cannot be empty"})
+		return
+	}
 
-	highestAutomatedBid, err := db.GetHighestAutomatedBid(c, auctionID)
-	if err == nil && highestAutomatedBid > 0 && currentHighestBidder != userID {
-		if highestAutomatedBid > bidRequest.Amount {
-			newBidAmount := bidRequest.Amount + 1
+	if startingBid := request.StartingBid; startingBid != nil {
+		switch {
+		case *startingBid <= 0:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Starting bid must be positive"})
+			return
+		case auction.ReservePrice > 0 && *startingBid > auction.ReservePrice:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Starting bid cannot be above the reserve price"})
+			return
+		case auction.BuyNowPrice > 0 && *startingBid >= auction.BuyNowPrice:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Starting bid must be below the buy now price"})
+			return
+		case auction.AuctionType == db.AuctionTypeDutch && *startingBid <= auction.Dutch.FloorPrice:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Starting bid must be above the Dutch floor price"})
+			return
+		}
+	}
 
-			_, err := db.CreateBid(c, auctionID, currentHighestBidder, newBidAmount)
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process automated bid"})
-				return
-			}
+	startTime, endTime := auction.StartTime, auction.EndTime
+	if request.StartTime != nil {
+		startTime = *request.StartTime
+	}
+	if request.EndTime != nil {
+		endTime = *request.EndTime
+	}
+	if !endTime.After(startTime) || endTime.Before(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "End time must be in the future and after the start time"})
+		return
+	}
 
-			if wsManager != nil {
-				updatedAuction, _ := db.GetAuctionByID(c, auctionID, userID)
-				bidDetails := map[string]interface{}{
-					"auction_id":  auctionID,
-					"amount":      newBidAmount,
-					"user_id":     currentHighestBidder,
-					"highest_bid": updatedAuction.CurrentHighestBid,
-					"current_user_bid": bidRequest.Amount,
-				}
-
-				wsManager.BroadcastNewBid(auctionID, bidDetails)
-			}
+	err = db.UpdateAuction(c, auctionID, request)
+	if errors.Is(err, db.ErrAuctionLocked) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Auctions can only be edited before the first bid"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update auction"})
+		return
+	}
 
-			c.JSON(http.StatusOK, gin.H{
-				"message": "Your bid was immediately outbid by an automated bidder",
-			})
-			return
-		}
+	updatedAuction, _ := db.GetAuctionByID(c, auctionID, userID)
+	if wsManager != nil && updatedAuction.Status != "draft" {
+		wsManager.BroadcastNewAuction(publicAuction(c, auctionID))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"auction": updatedAuction,
+		"message": "Auction updated successfully",
+	})
+}
+
+// PublishAuctionHandler publishes a draft. It goes live at its start time.
+func PublishAuctionHandler(c *gin.Context) {
+	userID, err := helpers.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	auctionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid auction ID"})
+		return
 	}
 
-	previousBidder, prevBidAmount, err := db.GetHighestBidder(c, auctionID)
-	if err == nil && previousBidder > 0 && previousBidder != userID {
-		prevBidderEmail, _ := db.GetUserEmail(c, previousBidder)
+	auction, err := db.GetAuctionByID(c, auctionID, userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Auction not found"})
+		return
+	}
+
+	if auction.SellerID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the seller can publish this auction"})
+		return
+	}
 
-		if prevBidderEmail != "" {
-			username, err := db.GetUserName(c, previousBidder)
+	status, err := db.PublishAuction(c, auctionID)
+	if errors.Is(err, db.ErrAuctionLocked) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only drafts that have not ended can be published"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to publish auction"})
+		return
+	}
 
-			go func() {
-				additionalData := map[string]interface{}{
-					"your_bid": prevBidAmount,
-					"new_bid":  bidRequest.Amount,
-					"username": username,
-					"error":    err,
-				}
+	announceListing(c, auctionID)
 
-				if err := helpers.SendAuctionEmail(c, prevBidderEmail, helpers.NotificationOutbid, auctionID, additionalData); err != nil {
-					// create_bid not affected by email sending
-				}
-			}()
+	if wsManager != nil {
+		updatedAuction := publicAuction(c, auctionID)
+		wsManager.BroadcastNewAuction(updatedAuction)
+		if status == "open" {
+			wsManager.BroadcastAuctionStatus(auctionID, "open", "", updatedAuction)
 		}
 	}
 
-	bidID, err := db.CreateBid(c, auctionID, userID, bidRequest.Amount)
+	updatedAuction, _ := db.GetAuctionByID(c, auctionID, userID)
+	c.JSON(http.StatusOK, gin.H{
+		"auction": updatedAuction,
+		"message": "Auction published successfully",
+	})
+}
+
+// PlaceBidHandler handles placing a bid on an auction
+func PlaceBidHandler(c *gin.Context) {
+	userID, err := helpers.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	auctionID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid auction ID"})
+		return
+	}
+
+	var bidRequest schema.BidCreate
+	if err := c.BindJSON(&bidRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+
+	quantity := bidRequest.Quantity
+	if quantity == 0 {
+		quantity = 1
+	}
+
+	result, ok := placeBid(c, auctionID, userID, bidRequest.Amount, quantity, false)
+	if !ok {
 		return
 	}
 
 	updatedAuction, _ := db.GetAuctionByID(c, auctionID, userID)
-	if wsManager != nil {
-		bidDetails := map[string]interface{}{
-			"auction_id":  auctionID,
-			"bid_id":      bidID,
-			"amount":      bidRequest.Amount,
-			"user_id":     userID,
-			"highest_bid": updatedAuction.CurrentHighestBid,
+	broadcastBid(auctionID, result, updatedAuction)
+
+	if result.Quantity > 0 {
+		c.JSON(http.StatusCreated, gin.H{
+			"auction":         updatedAuction,
+			"quantity":        result.Quantity,
+			"filled_quantity": result.Filled,
+			"message":         fmt.Sprintf("Bid placed successfully, %d of %d units currently filled", result.Filled, result.Quantity),
+		})
+		return
+	}
+
+	if result.Sealed {
+		message := "Sealed bid submitted successfully"
+		if result.Revised {
+			message = "Sealed bid revised successfully"
 		}
+		c.JSON(http.StatusCreated, gin.H{
+			"auction": updatedAuction,
+			"message": message,
+		})
+		return
+	}
 
-		wsManager.BroadcastNewBid(auctionID, bidDetails)
+	if result.Outcome == db.BidOutbidByProxy {
+		c.JSON(http.StatusOK, gin.H{
+			"auction": updatedAuction,
+			"message": "Your bid was immediately outbid by an automated bidder",
+		})
+		return
 	}
 
 	c.JSON(http.StatusCreated, gin.H{
-		"auction":  updatedAuction,
+		"auction": updatedAuction,
 		"message": "Bid placed successfully",
 	})
 }
 
-// GetBidsHandler retrieves all bids for a specific auction
+// BuyNowHandler buys an item at its buy-now price, closing the auction immediately
+func BuyNowHandler(c *gin.Context) {
+	userID, err := helpers.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	auctionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid auction ID"})
+		return
+	}
+
+	result, transactionID, err := db.BuyNow(c, auctionID, userID)
+	if errors.Is(err, db.ErrAuctionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Auction not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to complete purchase"})
+		return
+	}
+	if result.Outcome == db.BidRejected {
+		c.JSON(http.StatusBadRequest, gin.H{"error": result.Reason})
+		return
+	}
+
+	auction := publicAuction(c, auctionID)
+	if wsManager != nil {
+		wsManager.BroadcastAuctionStatus(auctionID, "closed", db.CloseReasonSold, auction)
+	}
+
+	notifyAuctionEnd(c, auction, userID, result.Amount, db.CloseReasonSold)
+	requestPayment(c, auctionID, transactionID, userID)
+
+	updatedAuction, _ := db.GetAuctionByID(c, auctionID, userID)
+	c.JSON(http.StatusCreated, gin.H{
+		"auction":        updatedAuction,
+		"transaction_id": transactionID,
+		"message":        "Item purchased successfully",
+	})
+}
+
+// AcceptDutchPriceHandler buys the item in a Dutch auction at the current price.
+// Only the first accept succeeds; it closes the auction.
+func AcceptDutchPriceHandler(c *gin.Context) {
+	userID, err := helpers.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	auctionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid auction ID"})
+		return
+	}
+
+	result, transactionID, err := db.AcceptDutchPrice(c, auctionID, userID)
+	if errors.Is(err, db.ErrAuctionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Auction not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept price"})
+		return
+	}
+	if result.Outcome == db.BidRejected {
+		c.JSON(http.StatusConflict, gin.H{"error": result.Reason})
+		return
+	}
+
+	auction := publicAuction(c, auctionID)
+	if wsManager != nil {
+		wsManager.BroadcastAuctionStatus(auctionID, "closed", db.CloseReasonSold, auction)
+	}
+
+	notifyAuctionEnd(c, auction, userID, result.Amount, db.CloseReasonSold)
+	requestPayment(c, auctionID, transactionID, userID)
+
+	updatedAuction, _ := db.GetAuctionByID(c, auctionID, userID)
+	c.JSON(http.StatusCreated, gin.H{
+		"auction":        updatedAuction,
+		"transaction_id": transactionID,
+		"message":        "Price accepted, item purchased successfully",
+	})
+}
+
+// GetBidsHandler retrieves all bids for a specific auction. While a sealed-bid
+// auction is running, only the caller's own bid is shown in full and bids
+// are listed by time, so neither the order nor the cursor reveals amounts.
 func GetBidsHandler(c *gin.Context) {
+	userID, err := helpers.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
 	auctionID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid auction ID"})
 		return
 	}
 
-	bids, err := db.GetBidsForAuction(c, auctionID)
+	auction, err := db.GetAuctionByID(c, auctionID, userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Auction not found"})
+		return
+	}
+
+	page, ok := pageRequest(c)
+	if !ok {
+		return
+	}
+
+	sealed := db.IsSealed(auction.AuctionType) && auction.Status != "closed"
+	bids, err := db.GetBidsForAuction(c, auctionID, sealed, page)
+	if errors.Is(err, db.ErrInvalidSort) && sealed {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bids on a sealed auction can only be sorted by time until it closes"})
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve bids"})
+		respondPageError(c, err, "Failed to retrieve bids")
 		return
 	}
 
+	if sealed {
+		for i := range bids.Items {
+			if bids.Items[i].BuyerID != userID {
+				bids.Items[i].BuyerID = 0
+				bids.Items[i].BuyerName = ""
+				bids.Items[i].Amount = 0
+			}
+		}
+	}
+
 	c.JSON(http.StatusOK, bids)
 }
 
+// RelistAuctionHandler puts the item of an unsold auction up for auction again.
+// The old auction and its bids are kept.
+func RelistAuctionHandler(c *gin.Context) {
+	userID, err := helpers.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	auctionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid auction ID"})
+		return
+	}
+
+	var request schema.RelistRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+
+	auction, err := db.GetAuctionByID(c, auctionID, userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Auction not found"})
+		return
+	}
+
+	if auction.SellerID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the seller can relist this item"})
+		return
+	}
+
+	if !request.EndTime.After(request.StartTime) || request.EndTime.Before(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "End time must be in the future and after the start time"})
+		return
+	}
+
+	if request.StartingBid < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Starting bid must be positive"})
+		return
+	}
+
+	if startingBid := request.StartingBid; startingBid > 0 {
+		if auction.ReservePrice > 0 && startingBid > auction.ReservePrice {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Starting bid cannot be above the reserve price"})
+			return
+		}
+		if auction.BuyNowPrice > 0 && startingBid >= auction.BuyNowPrice {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Starting bid must be below the buy now price"})
+			return
+		}
+		if auction.AuctionType == db.AuctionTypeDutch && startingBid <= auction.Dutch.FloorPrice {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Starting bid must be above the Dutch floor price"})
+			return
+		}
+	}
+
+	newAuctionID, err := db.RelistAuction(c, auctionID, request.StartTime, request.EndTime, request.StartingBid, false)
+	if errors.Is(err, db.ErrNotRelistable) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only unsold auctions that have not been relisted yet can be relisted"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to relist auction"})
+		return
+	}
+
+	announceListing(c, newAuctionID)
+
+	if wsManager != nil {
+		wsManager.BroadcastNewAuction(publicAuction(c, newAuctionID))
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"auction_id":    newAuctionID,
+		"relisted_from": auctionID,
+		"message":       "Auction relisted successfully",
+	})
+}
+
+// autoRelist relists an auction that closed without bids if the seller asked
+// for it, keeping the auction's original duration
+func autoRelist(c *gin.Context, auction schema.AuctionResponse, closeReason string) {
+	if closeReason != db.CloseReasonNoBids || auction.AutoRelist == 0 {
+		return
+	}
+
+	start := time.Now()
+	end := start.Add(auction.EndTime.Sub(auction.StartTime))
+	newAuctionID, err := db.RelistAuction(c, auction.AuctionID, start, end, 0, true)
+	if err != nil {
+		fmt.Printf("Failed to relist auction %d: %v\n", auction.AuctionID, err)
+		return
+	}
+
+	announceListing(c, newAuctionID)
+
+	if wsManager != nil {
+		wsManager.BroadcastNewAuction(publicAuction(c, newAuctionID))
+	}
+}
+
 // DeleteAuctionHandler handles deleting an auction (setting status to deleted)
 func DeleteAuctionHandler(c *gin.Context) {
 	userID, err := helpers.GetUserID(c)
@@ -314,219 +854,373 @@ func UpdateAuctionEndTimeHandler(c *gin.Context) {
 	}
 
 	if wsManager != nil {
-		wsManager.BroadcastNewAuction(updatedAuction)
+		wsManager.BroadcastNewAuction(publicAuction(c, auctionID))
 	}
 
 	c.JSON(http.StatusOK, updatedAuction)
 }
 
-// EndAuctionsHandler processes auctions that have ended or should be opened
+// EndAuctionsHandler processes auctions that have ended or should be opened,
+// steps the prices of running Dutch auctions and expires second-chance offers
 func EndAuctionsHandler(c *gin.Context) {
-    auctionsToOpen, err := db.GetAuctionsToOpen(c)
-    if err != nil {
-        fmt.Printf("Failed to get auctions to open: %v\n", err)
-    } else {
-        for _, auction := range auctionsToOpen {
-            err = db.UpdateAuctionStatus(c, auction.AuctionID, "open")
-            if err != nil {
-                continue
-            }
-            
-            if wsManager != nil {
-                updatedAuction, _ := db.GetAuctionByID(c, auction.AuctionID, auction.SellerID)
-                wsManager.BroadcastNewAuction(updatedAuction)
-				wsManager.BroadcastAuctionStatus(auction.AuctionID, "open", updatedAuction)
-            }
-        }
-    }
-
-    endedAuctions, err := db.GetAuctionsToClose(c)
-    if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get ended auctions"})
-        return
-    }
-
-    for _, auction := range endedAuctions {
-        winnerID, highestBid, err := db.GetHighestBidder(c, auction.AuctionID)
-        if err != nil {
-            continue
-        }
-
-        err = db.UpdateAuctionStatus(c, auction.AuctionID, "closed")
-        if err != nil {
-            continue
-        }
+	auctionsToOpen, err := db.GetAuctionsToOpen(c)
+	if err != nil {
+		fmt.Printf("Failed to get auctions to open: %v\n", err)
+	} else {
+		for _, auction := range auctionsToOpen {
+			err = db.UpdateAuctionStatus(c, auction.AuctionID, "open")
+			if err != nil {
+				continue
+			}
+
+			announceListing(c, auction.AuctionID)
+
+			if wsManager != nil {
+				updatedAuction := publicAuction(c, auction.AuctionID)
+				wsManager.BroadcastNewAuction(updatedAuction)
+				wsManager.BroadcastAuctionStatus(auction.AuctionID, "open", "", updatedAuction)
+			}
+		}
+	}
+
+	priceDrops, err := db.StepDutchPrices(c)
+	if err != nil {
+		fmt.Printf("Failed to step Dutch auction prices: %v\n", err)
+	} else if wsManager != nil {
+		for _, drop := range priceDrops {
+			wsManager.BroadcastPriceDrop(drop.AuctionID, drop.Price)
+		}
+	}
+
+	if err := db.ExpireSecondChanceOffers(c); err != nil {
+		fmt.Printf("Failed to expire second-chance offers: %v\n", err)
+	}
+
+	failUnpaidPayments(c)
+	autoConfirmDeliveries(c)
+
+	sendEndingSoonReminders(c)
+	sendSavedSearchDigests(c)
+
+	if err := db.RefreshStaleReputations(c); err != nil {
+		fmt.Printf("Failed to refresh seller reputations: %v\n", err)
+	}
+
+	endedAuctions, err := db.GetAuctionsToClose(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get ended auctions"})
+		return
+	}
+
+	for _, auction := range endedAuctions {
+		if auction.Quantity > 1 {
+			closeMultiUnitAuction(c, auction)
+			continue
+		}
+
+		winnerID, highestBid, price, err := db.GetAuctionWinner(c, auction)
+		if err != nil {
+			continue
+		}
+
+		closeReason := db.CloseReasonSold
+		if winnerID == 0 || highestBid == 0 {
+			closeReason = db.CloseReasonNoBids
+		} else if auction.ReservePrice > 0 && highestBid < auction.ReservePrice {
+			closeReason = db.CloseReasonReserveNotMet
+		}
+
+		var sales []db.Sale
+		if closeReason == db.CloseReasonSold {
+			sales = []db.Sale{{BuyerID: winnerID, Quantity: 1, Amount: price}}
+		}
+
+		transactionIDs, err := db.CloseAuction(c, auction.AuctionID, closeReason, sales)
+		if err != nil {
+			continue
+		}
 
 		if wsManager != nil {
-            updatedAuction, _ := db.GetAuctionByID(c, auction.AuctionID, auction.SellerID)
-            wsManager.BroadcastAuctionStatus(auction.AuctionID, "closed", updatedAuction)
-        }
-
-        if winnerID > 0 && highestBid > 0 {
-            _, err := db.CreateTransaction(c, auction.AuctionID)
-            if err != nil {
-                continue
-            }
-		}
-
-		sellerEmail, _ := db.GetUserEmail(c, auction.SellerID)
-		sellerUsername, _ := db.GetUserName(c, auction.SellerID)
-		if sellerEmail != "" {
-			go func() {
-				additionalData := map[string]interface{}{
-					"is_seller":      true,
-					"username":       sellerUsername,
-				}
-
-				if winnerID > 0 {
-					winnerName, _ := db.GetUserName(c, winnerID)
-					additionalData["winner_name"] = winnerName
-					additionalData["highest_bid"] =     highestBid
-				}
-
-				helpers.SendAuctionEmail(c, sellerEmail, helpers.NotificationAuctionEnd, auction.AuctionID, additionalData)
-			}()
-		}
-
-		winnerEmail, _ := db.GetUserEmail(c, winnerID)
-		winnerUsername, _ := db.GetUserName(c, winnerID)
-		if winnerEmail != "" {
-			winnerEmailCopy := winnerEmail
-			
-			go func() {
-				additionalData := map[string]interface{}{
-					"is_winner":      true,
-					"username":       winnerUsername,
-					"highest_bid":    highestBid,
-				}
-
-				helpers.SendAuctionEmail(c, winnerEmailCopy, helpers.NotificationAuctionEnd, auction.AuctionID, additionalData)
-			}()
-		}
-    }
-
-    c.JSON(http.StatusOK, gin.H{
-        "message": "Processed auctions",
-    })
+			wsManager.BroadcastAuctionStatus(auction.AuctionID, "closed", closeReason, publicAuction(c, auction.AuctionID))
+		}
+
+		autoRelist(c, auction, closeReason)
+
+		notifyAuctionEnd(c, auction, winnerID, price, closeReason)
+		for _, transactionID := range transactionIDs {
+			requestPayment(c, auction.AuctionID, transactionID, winnerID)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Processed auctions",
+	})
+}
+
+// closeMultiUnitAuction clears an ended multi-quantity auction at the uniform
+// price, closing it together with a transaction for each winning bidder
+func closeMultiUnitAuction(c *gin.Context, auction schema.AuctionResponse) {
+	winners, clearingPrice, err := db.GetUnitWinners(c, auction)
+	if err != nil {
+		return
+	}
+
+	closeReason := db.CloseReasonSold
+	if len(winners) == 0 {
+		closeReason = db.CloseReasonNoBids
+	} else if auction.ReservePrice > 0 && clearingPrice < auction.ReservePrice {
+		closeReason = db.CloseReasonReserveNotMet
+	}
+
+	var sales []db.Sale
+	if closeReason == db.CloseReasonSold {
+		for _, winner := range winners {
+			sales = append(sales, db.Sale{
+				BuyerID:  winner.BuyerID,
+				Quantity: winner.Quantity,
+				Amount:   clearingPrice * float64(winner.Quantity),
+			})
+		}
+	}
+
+	transactionIDs, err := db.CloseAuction(c, auction.AuctionID, closeReason, sales)
+	if err != nil {
+		return
+	}
+
+	if wsManager != nil {
+		wsManager.BroadcastAuctionStatus(auction.AuctionID, "closed", closeReason, publicAuction(c, auction.AuctionID))
+	}
+
+	if closeReason != db.CloseReasonSold {
+		autoRelist(c, auction, closeReason)
+		notifyAuctionEnd(c, auction, 0, 0, closeReason)
+		return
+	}
+
+	var winnerNames []string
+	for i, winner := range winners {
+		winnerName, _ := db.GetUserName(c, winner.BuyerID)
+		winnerNames = append(winnerNames, fmt.Sprintf("%s (%d units)", winnerName, winner.Quantity))
+		notifyBidderOfEnd(c, auction, winner.BuyerID, winnerName, clearingPrice, closeReason)
+		requestPayment(c, auction.AuctionID, transactionIDs[i], winner.BuyerID)
+	}
+
+	notifySellerOfEnd(c, auction, strings.Join(winnerNames, ", "), clearingPrice, closeReason)
+}
+
+// notifyAuctionEnd emails the seller and the highest bidder about how an auction closed
+func notifyAuctionEnd(c *gin.Context, auction schema.AuctionResponse, winnerID int, highestBid float64, closeReason string) {
+	winnerName := ""
+	if winnerID > 0 {
+		winnerName, _ = db.GetUserName(c, winnerID)
+	}
+
+	notifySellerOfEnd(c, auction, winnerName, highestBid, closeReason)
+
+	if winnerID > 0 {
+		notifyBidderOfEnd(c, auction, winnerID, winnerName, highestBid, closeReason)
+	}
+}
+
+// notifySellerOfEnd emails the seller how their auction closed. winnerName is
+// empty when nobody bid.
+func notifySellerOfEnd(c *gin.Context, auction schema.AuctionResponse, winnerName string, highestBid float64, closeReason string) {
+	sellerEmail, _ := db.GetUserEmail(c, auction.SellerID)
+	if sellerEmail == "" {
+		return
+	}
+	sellerUsername, _ := db.GetUserName(c, auction.SellerID)
+
+	go func() {
+		additionalData := map[string]interface{}{
+			"is_seller":       true,
+			"username":        sellerUsername,
+			"reserve_not_met": closeReason == db.CloseReasonReserveNotMet,
+		}
+
+		if winnerName != "" {
+			additionalData["winner_name"] = winnerName
+			additionalData["highest_bid"] = highestBid
+		}
+
+		helpers.SendAuctionEmail(c, sellerEmail, helpers.NotificationAuctionEnd, auction.AuctionID, additionalData)
+	}()
+}
+
+// notifyBidderOfEnd emails a winning bidder, or the high bidder when the reserve was not met
+func notifyBidderOfEnd(c *gin.Context, auction schema.AuctionResponse, bidderID int, bidderName string, highestBid float64, closeReason string) {
+	bidderEmail, _ := db.GetUserEmail(c, bidderID)
+	if bidderEmail == "" {
+		return
+	}
+	reserveNotMet := closeReason == db.CloseReasonReserveNotMet
+
+	go func() {
+		additionalData := map[string]interface{}{
+			"username":        bidderName,
+			"highest_bid":     highestBid,
+			"reserve_not_met": reserveNotMet,
+		}
+
+		if reserveNotMet {
+			additionalData["is_high_bidder"] = true
+		} else {
+			additionalData["is_winner"] = true
+		}
+
+		helpers.SendAuctionEmail(c, bidderEmail, helpers.NotificationAuctionEnd, auction.AuctionID, additionalData)
+	}()
 }
 
 // PlaceAutomatedBidHandler handles placing an automated bid on an auction
 func PlaceAutomatedBidHandler(c *gin.Context) {
-    userID, err := helpers.GetUserID(c)
-    if err != nil {
-        c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
-        return
-    }
-
-    auctionID, err := strconv.Atoi(c.Param("id"))
-    if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid auction ID"})
-        return
-    }
-
-    var bidRequest schema.AutomatedBidCreate
-    if err := c.BindJSON(&bidRequest); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
-        return
-    }
-
-    auction, err := db.GetAuctionByID(c, auctionID, userID)
-    if err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Auction not found"})
-        return
-    }
-
-    if auction.Status != "open" {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot bid on a closed or deleted auction"})
-        return
-    }
-
-    if bidRequest.Amount <= auction.CurrentHighestBid {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Automated bid amount must be higher than current highest bid"})
-        return
-    }
-
-    var currentHighestBidder int
-    currentHighestBidder, err = db.GetCurrentHighestBidder(c, auctionID)
-
-    if currentHighestBidder == userID {
-        err = db.UpdateAutomatedBid(c, userID, auctionID, bidRequest.Amount)
-        if err != nil {
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update automated bid"})
-            return
-        }
-
-        c.JSON(http.StatusOK, gin.H{
-            "message": "Automated bid updated successfully",
-        })
-        return
-    }
-
-    highestAutomatedBid, err := db.GetHighestAutomatedBid(c, auctionID)
-    
-    if highestAutomatedBid > 0 && highestAutomatedBid >= bidRequest.Amount {
-        c.JSON(http.StatusOK, gin.H{
-            "message": "An automated bid higher than or equal to your automated bid already exists in the system",
-        })
-        return
-    }
-
-    var bidAmount float64
-    if highestAutomatedBid > 0 {
-        bidAmount = highestAutomatedBid + 1
-    } else if auction.CurrentHighestBid > 0 {
-        bidAmount = auction.CurrentHighestBid + 1
-    } else {
-        bidAmount = auction.StartingBid + 1
-    }
-
-    bidID, err := db.CreateBid(c, auctionID, userID, bidAmount)
-    if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set automated bid"})
-        return
-    }
-
-    err = db.UpdateAutomatedBid(c, userID, auctionID, bidRequest.Amount)
-    if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set automated bid"})
-        return
-    }
-
-    if currentHighestBidder > 0 {
-        prevBidderEmail, err := db.GetUserEmail(c, currentHighestBidder)
-        if err == nil && prevBidderEmail != "" {
-            username, _ := db.GetUserName(c, currentHighestBidder)
-
-            go func() {
-                additionalData := map[string]interface{}{
-                    "your_bid": auction.CurrentHighestBid,
-                    "new_bid":  bidAmount,
-                    "username": username,
-                }
-
-                if err := helpers.SendAuctionEmail(c, prevBidderEmail, helpers.NotificationOutbid, auctionID, additionalData); err != nil {
-                    fmt.Printf("Failed to send outbid notification: %v\n", err)
-                }
-            }()
-        }
-    }
+	userID, err := helpers.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	auctionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid auction ID"})
+		return
+	}
+
+	var bidRequest schema.AutomatedBidCreate
+	if err := c.BindJSON(&bidRequest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+
+	result, ok := placeBid(c, auctionID, userID, bidRequest.Amount, 1, true)
+	if !ok {
+		return
+	}
 
 	updatedAuction, _ := db.GetAuctionByID(c, auctionID, userID)
-    if wsManager != nil {
-        bidDetails := map[string]interface{}{
-            "auction_id":  auctionID,
-            "bid_id":      bidID,
-            "amount":      bidAmount,
-            "user_id":     userID,
-            "highest_bid": updatedAuction.CurrentHighestBid,
-			"current_automated_bid": bidRequest.Amount,
-        }
-
-        wsManager.BroadcastNewBid(auctionID, bidDetails)
-    }
-
-    c.JSON(http.StatusCreated, gin.H{
-        "auction":  updatedAuction,
-        "message": "Automated bid placed successfully",
-    })
+
+	if result.BidID == 0 {
+		c.JSON(http.StatusOK, gin.H{
+			"auction": updatedAuction,
+			"message": "Automated bid updated successfully",
+		})
+		return
+	}
+
+	broadcastBid(auctionID, result, updatedAuction)
+
+	if result.Outcome == db.BidOutbidByProxy {
+		c.JSON(http.StatusOK, gin.H{
+			"auction": updatedAuction,
+			"message": "An automated bid higher than your automated bid already exists",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"auction": updatedAuction,
+		"message": "Automated bid placed successfully",
+	})
+}
+
+// placeBid runs the bid engine and writes the error response when the bid is
+// not recorded. It also emails the previous leader if they lost the lead, and
+// any bidders pushed out of the winning set of a multi-quantity auction.
+func placeBid(c *gin.Context, auctionID, userID int, amount float64, quantity int, automated bool) (db.BidResult, bool) {
+	result, err := db.PlaceBid(c, auctionID, userID, amount, quantity, automated)
+	if errors.Is(err, db.ErrAuctionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Auction not found"})
+		return result, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to place bid"})
+		return result, false
+	}
+	if result.Outcome == db.BidRejected {
+		c.JSON(http.StatusBadRequest, gin.H{"error": result.Reason, "next_min_bid": result.NextMinBid})
+		return result, false
+	}
+
+	previousBidder := result.PreviousBidder
+	if previousBidder > 0 && previousBidder != result.HighestBidder {
+		notifyOutbid(c, auctionID, previousBidder, result.PreviousBid, result.HighestBid)
+	}
+
+	for bidderID, yourBid := range result.Displaced {
+		notifyOutbid(c, auctionID, bidderID, yourBid, result.HighestBid)
+	}
+
+	return result, true
+}
+
+// notifyOutbid emails a bidder that their bid is no longer winning
+func notifyOutbid(c *gin.Context, auctionID, bidderID int, yourBid, newBid float64) {
+	bidderEmail, _ := db.GetUserEmail(c, bidderID)
+	if bidderEmail == "" {
+		return
+	}
+	username, _ := db.GetUserName(c, bidderID)
+
+	go func() {
+		additionalData := map[string]interface{}{
+			"your_bid": yourBid,
+			"new_bid":  newBid,
+			"username": username,
+		}
+
+		if err := helpers.SendAuctionEmail(c, bidderEmail, helpers.NotificationOutbid, auctionID, additionalData); err != nil {
+			fmt.Printf("Failed to send outbid notification: %v\n", err)
+		}
+	}()
+}
+
+// announceListing tells saved-search owners and the seller's followers about
+// an auction that has just opened. It does nothing for auctions not yet open.
+func announceListing(c *gin.Context, auctionID int) {
+	alertSavedSearches(c, auctionID)
+	notifyFollowers(c, auctionID)
+}
+
+// publicAuction loads an auction as seen by a user with no stake in it, which
+// is what websocket broadcasts should carry
+func publicAuction(c *gin.Context, auctionID int) schema.AuctionResponse {
+	auction, _ := db.GetAuctionByID(c, auctionID, 0)
+	return auction
+}
+
+// broadcastBid pushes the bids recorded by the engine to websocket clients,
+// along with any soft-close extension they caused
+func broadcastBid(auctionID int, result db.BidResult, auction schema.AuctionResponse) {
+	if wsManager == nil {
+		return
+	}
+
+	if result.Sealed {
+		wsManager.BroadcastNewBid(auctionID, map[string]interface{}{
+			"auction_id": auctionID,
+			"sealed":     true,
+		})
+		return
+	}
+
+	bidDetails := map[string]interface{}{
+		"auction_id":  auctionID,
+		"bid_id":      result.BidID,
+		"amount":      result.HighestBid,
+		"user_id":     result.HighestBidder,
+		"highest_bid": auction.CurrentHighestBid,
+		"outcome":     result.Outcome,
+		"end_time":    auction.EndTime,
+	}
+	if result.Quantity > 0 {
+		bidDetails["quantity"] = result.Quantity
+		bidDetails["next_min_bid"] = result.NextMinBid
+	}
+
+	wsManager.BroadcastNewBid(auctionID, bidDetails)
+
+	if result.Extended {
+		wsManager.BroadcastAuctionExtended(auctionID, result.EndTime)
+	}
 }
@@ -0,0 +1,169 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"Online-Auction-System/backend/internal/db"
+	"Online-Auction-System/backend/internal/schema"
+)
+
+const (
+	maxTags      = 10
+	maxTagLength = 50
+)
+
+// GetCategoriesHandler returns the category tree with open auction counts
+func GetCategoriesHandler(c *gin.Context) {
+	categories, err := db.GetCategoryTree(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve categories"})
+		return
+	}
+
+	c.JSON(http.StatusOK, categories)
+}
+
+// GetCategoryHandler returns one category with its subcategories and open auction counts
+func GetCategoryHandler(c *gin.Context) {
+	categoryID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		return
+	}
+
+	category, err := db.GetCategory(c, categoryID)
+	if errors.Is(err, db.ErrCategoryNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve category"})
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+// CreateCategoryHandler adds a category (admin only)
+func CreateCategoryHandler(c *gin.Context) {
+	var request schema.CategoryRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+
+	if !validParentCategory(c, request.ParentID) {
+		return
+	}
+
+	categoryID, err := db.CreateCategory(c, strings.TrimSpace(request.Name), request.ParentID)
+	if err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "A category with this name already exists here"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"category_id": categoryID,
+		"message":     "Category created successfully",
+	})
+}
+
+// UpdateCategoryHandler renames or moves a category (admin only)
+func UpdateCategoryHandler(c *gin.Context) {
+	categoryID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		return
+	}
+
+	var request schema.CategoryRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+
+	if !validParentCategory(c, request.ParentID) {
+		return
+	}
+
+	err = db.UpdateCategory(c, categoryID, strings.TrimSpace(request.Name), request.ParentID)
+	switch {
+	case errors.Is(err, db.ErrCategoryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		return
+	case errors.Is(err, db.ErrCategoryCycle):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A category cannot be moved under itself"})
+		return
+	case err != nil:
+		c.JSON(http.StatusConflict, gin.H{"error": "A category with this name already exists here"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Category updated successfully"})
+}
+
+// DeleteCategoryHandler removes an empty category (admin only)
+func DeleteCategoryHandler(c *gin.Context) {
+	categoryID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		return
+	}
+
+	err = db.DeleteCategory(c, categoryID)
+	switch {
+	case errors.Is(err, db.ErrCategoryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		return
+	case errors.Is(err, db.ErrCategoryInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": "Only categories without subcategories or items can be deleted"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete category"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Category deleted successfully"})
+}
+
+// validParentCategory writes an error response and returns false if parentID
+// names a category that does not exist
+func validParentCategory(c *gin.Context, parentID *int) bool {
+	if parentID == nil {
+		return true
+	}
+
+	exists, err := db.CategoryExists(c, *parentID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check parent category"})
+		return false
+	}
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Parent category does not exist"})
+		return false
+	}
+	return true
+}
+
+// normalizeTags lowercases and trims tags, dropping blanks and duplicates
+func normalizeTags(tags []string) ([]string, bool) {
+	seen := make(map[string]bool)
+	var normalized []string
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if len(tag) > maxTagLength {
+			return nil, false
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized, len(normalized) <= maxTags
+}
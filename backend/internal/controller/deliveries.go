@@ -0,0 +1,345 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"Online-Auction-System/backend/config"
+	"Online-Auction-System/backend/internal/db"
+	"Online-Auction-System/backend/internal/helpers"
+	"Online-Auction-System/backend/internal/schema"
+)
+
+const maxDisputeLength = 2000
+
+// GetDeliveriesHandler lists the deliveries the current user is sending or receiving
+func GetDeliveriesHandler(c *gin.Context) {
+	userID, err := helpers.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	page, ok := pageRequest(c)
+	if !ok {
+		return
+	}
+
+	deliveries, err := db.GetDeliveries(c, userID, page)
+	if err != nil {
+		respondPageError(c, err, "Failed to retrieve deliveries")
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// GetDeliveryHandler shows one delivery to its buyer or seller
+func GetDeliveryHandler(c *gin.Context) {
+	delivery, _, ok := partyDelivery(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
+
+// ShipDeliveryHandler lets the seller mark a paid item as shipped
+func ShipDeliveryHandler(c *gin.Context) {
+	delivery, userID, ok := partyDelivery(c)
+	if !ok {
+		return
+	}
+
+	if delivery.SellerID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the seller can mark an item shipped"})
+		return
+	}
+
+	var request schema.ShipmentRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+
+	request.Carrier = strings.TrimSpace(request.Carrier)
+	request.TrackingNumber = strings.TrimSpace(request.TrackingNumber)
+	if request.Carrier == "" || len(request.Carrier) > 50 || request.TrackingNumber == "" || len(request.TrackingNumber) > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Carrier must be 1 to 50 characters and tracking number 1 to 100"})
+		return
+	}
+
+	err := db.ShipDelivery(c, delivery.DeliveryID, request.Carrier, request.TrackingNumber)
+	if !respondDeliveryUpdate(c, err, "Only pending deliveries can be shipped") {
+		return
+	}
+
+	delivery.Status = db.DeliveryShipped
+	delivery.Carrier = &request.Carrier
+	delivery.TrackingNumber = &request.TrackingNumber
+	notifyDeliveryUpdate(c, delivery, delivery.BuyerID, delivery.BuyerName)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Item marked as shipped"})
+}
+
+// ConfirmDeliveryHandler lets the buyer confirm they received a shipped item
+func ConfirmDeliveryHandler(c *gin.Context) {
+	delivery, userID, ok := partyDelivery(c)
+	if !ok {
+		return
+	}
+
+	if delivery.BuyerID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the buyer can confirm delivery"})
+		return
+	}
+
+	err := db.ConfirmDelivery(c, delivery.DeliveryID)
+	if !respondDeliveryUpdate(c, err, "Only shipped deliveries can be confirmed") {
+		return
+	}
+
+	delivery.Status = db.DeliveryDelivered
+	notifyDeliveryUpdate(c, delivery, delivery.SellerID, delivery.SellerName)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Delivery confirmed"})
+}
+
+// DisputeDeliveryHandler lets the buyer dispute a shipped item, which stops
+// it being confirmed automatically
+func DisputeDeliveryHandler(c *gin.Context) {
+	delivery, userID, ok := partyDelivery(c)
+	if !ok {
+		return
+	}
+
+	if delivery.BuyerID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the buyer can dispute a delivery"})
+		return
+	}
+
+	var request schema.DisputeRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+
+	request.Reason = strings.TrimSpace(request.Reason)
+	if request.Reason == "" || len(request.Reason) > maxDisputeLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Reason must be 1 to 2000 characters"})
+		return
+	}
+
+	err := db.DisputeDelivery(c, delivery.DeliveryID, request.Reason)
+	if !respondDeliveryUpdate(c, err, "Only shipped deliveries that are not already disputed can be disputed") {
+		return
+	}
+
+	delivery.DisputeReason = &request.Reason
+	notifyDeliveryUpdate(c, delivery, delivery.SellerID, delivery.SellerName)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Delivery disputed"})
+}
+
+// AcceptDisputeHandler lets the seller accept the buyer's dispute, which
+// refunds the buyer
+func AcceptDisputeHandler(c *gin.Context) {
+	delivery, userID, ok := partyDelivery(c)
+	if !ok {
+		return
+	}
+
+	if delivery.SellerID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the seller can accept a dispute"})
+		return
+	}
+
+	err := db.RefundDelivery(c, delivery.DeliveryID)
+	if !respondDeliveryUpdate(c, err, "Only open disputes can be accepted") {
+		return
+	}
+
+	notifyDisputeResolved(c, delivery, db.DeliveryRefunded)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Dispute accepted and buyer refunded"})
+}
+
+// GetDisputesHandler lists the open disputes for admins
+func GetDisputesHandler(c *gin.Context) {
+	page, ok := pageRequest(c)
+	if !ok {
+		return
+	}
+
+	disputes, err := db.GetDisputes(c, page)
+	if err != nil {
+		respondPageError(c, err, "Failed to retrieve disputes")
+		return
+	}
+
+	c.JSON(http.StatusOK, disputes)
+}
+
+// ResolveDisputeHandler lets an admin resolve a dispute by refunding the
+// buyer or releasing the payment to the seller
+func ResolveDisputeHandler(c *gin.Context) {
+	deliveryID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid delivery ID"})
+		return
+	}
+
+	var request schema.DisputeResolutionRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+
+	delivery, err := db.GetDelivery(c, deliveryID, 0)
+	if errors.Is(err, db.ErrDeliveryNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Delivery not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve delivery"})
+		return
+	}
+
+	var status string
+	switch request.Outcome {
+	case "refund":
+		err = db.RefundDelivery(c, deliveryID)
+		status = db.DeliveryRefunded
+	case "release":
+		err = db.ReleaseDisputedDelivery(c, deliveryID)
+		status = db.DeliveryDelivered
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Outcome must be refund or release"})
+		return
+	}
+	if !respondDeliveryUpdate(c, err, "Only open disputes can be resolved") {
+		return
+	}
+
+	notifyDisputeResolved(c, delivery, status)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Dispute resolved"})
+}
+
+// partyDelivery loads the delivery named in the URL if the current user is
+// its buyer or seller. It writes an error response and returns false otherwise.
+func partyDelivery(c *gin.Context) (schema.DeliveryResponse, int, bool) {
+	userID, err := helpers.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return schema.DeliveryResponse{}, 0, false
+	}
+
+	deliveryID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid delivery ID"})
+		return schema.DeliveryResponse{}, 0, false
+	}
+
+	delivery, err := db.GetDelivery(c, deliveryID, userID)
+	if errors.Is(err, db.ErrDeliveryNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Delivery not found"})
+		return delivery, 0, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve delivery"})
+		return delivery, 0, false
+	}
+
+	return delivery, userID, true
+}
+
+// respondDeliveryUpdate writes the error response for a failed delivery
+// change, with conflict as the message when the delivery was in the wrong
+// state. It returns true if there was no error.
+func respondDeliveryUpdate(c *gin.Context, err error, conflict string) bool {
+	switch {
+	case errors.Is(err, db.ErrDeliveryTransition):
+		c.JSON(http.StatusConflict, gin.H{"error": conflict})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update delivery"})
+	default:
+		return true
+	}
+	return false
+}
+
+// notifyDeliveryUpdate emails a party of a delivery about its new status
+func notifyDeliveryUpdate(c *gin.Context, delivery schema.DeliveryResponse, receiverID int, receiverName string) {
+	receiverEmail, _ := db.GetUserEmail(c, receiverID)
+	if receiverEmail == "" {
+		return
+	}
+
+	go func() {
+		additionalData := map[string]interface{}{
+			"username":        receiverName,
+			"is_seller":       receiverID == delivery.SellerID,
+			"buyer_name":      delivery.BuyerName,
+			"delivery_status": delivery.Status,
+			"auto_confirmed":  delivery.AutoConfirmed,
+		}
+
+		if delivery.Carrier != nil {
+			additionalData["carrier"] = *delivery.Carrier
+			additionalData["tracking_number"] = *delivery.TrackingNumber
+		}
+		if delivery.DisputeReason != nil {
+			additionalData["dispute_reason"] = *delivery.DisputeReason
+		}
+		if delivery.ResolvedAt != nil {
+			additionalData["dispute_resolved"] = true
+		}
+
+		helpers.SendAuctionEmail(c, receiverEmail, helpers.NotificationDeliveryUpdate, delivery.AuctionID, additionalData)
+	}()
+}
+
+// notifyDeliveryCreated tells both sides of a paid sale that it is waiting to
+// be shipped
+func notifyDeliveryCreated(c *gin.Context, deliveryID int) {
+	delivery, err := db.GetDelivery(c, deliveryID, 0)
+	if err != nil {
+		fmt.Printf("Failed to load delivery %d for notification: %v\n", deliveryID, err)
+		return
+	}
+
+	notifyDeliveryUpdate(c, delivery, delivery.SellerID, delivery.SellerName)
+	notifyDeliveryUpdate(c, delivery, delivery.BuyerID, delivery.BuyerName)
+}
+
+// notifyDisputeResolved tells both sides of a dispute how it was resolved
+func notifyDisputeResolved(c *gin.Context, delivery schema.DeliveryResponse, status string) {
+	now := time.Now()
+	delivery.Status = status
+	delivery.ResolvedAt = &now
+
+	notifyDeliveryUpdate(c, delivery, delivery.SellerID, delivery.SellerName)
+	notifyDeliveryUpdate(c, delivery, delivery.BuyerID, delivery.BuyerName)
+}
+
+// autoConfirmDeliveries confirms the shipments nobody confirmed or disputed in
+// time and tells both sides
+func autoConfirmDeliveries(c *gin.Context) {
+	deliveries, err := db.AutoConfirmDeliveries(c, config.DeliveryConfirmWindow())
+	if err != nil {
+		fmt.Printf("Failed to auto-confirm deliveries: %v\n", err)
+		return
+	}
+
+	for _, delivery := range deliveries {
+		notifyDeliveryUpdate(c, delivery, delivery.SellerID, delivery.SellerName)
+		notifyDeliveryUpdate(c, delivery, delivery.BuyerID, delivery.BuyerName)
+	}
+}
@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"Online-Auction-System/backend/internal/db"
+	"Online-Auction-System/backend/internal/helpers"
+)
+
+// GetBalanceHandler shows the current user's seller balance: what has been
+// released to them and what is still held in escrow
+func GetBalanceHandler(c *gin.Context) {
+	userID, err := helpers.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	balance, err := db.GetSellerBalance(c, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve balance"})
+		return
+	}
+
+	c.JSON(http.StatusOK, balance)
+}
+
+// GetLedgerHandler lists the escrow ledger entries for the current user's
+// purchases and sales
+func GetLedgerHandler(c *gin.Context) {
+	userID, err := helpers.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	page, ok := pageRequest(c)
+	if !ok {
+		return
+	}
+
+	entries, err := db.GetLedgerEntries(c, userID, page)
+	if err != nil {
+		respondPageError(c, err, "Failed to retrieve ledger")
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// GetLedgerAccountsHandler lets admins audit the balances of every ledger account
+func GetLedgerAccountsHandler(c *gin.Context) {
+	audit, err := db.GetLedgerAccounts(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve ledger accounts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, audit)
+}
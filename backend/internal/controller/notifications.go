@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"Online-Auction-System/backend/internal/db"
+	"Online-Auction-System/backend/internal/helpers"
+)
+
+// GetNotificationsHandler lists the current user's in-app notifications.
+// ?unread=true leaves out those already read.
+func GetNotificationsHandler(c *gin.Context) {
+	userID, err := helpers.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	page, ok := pageRequest(c)
+	if !ok {
+		return
+	}
+
+	notifications, err := db.GetNotifications(c, userID, c.Query("unread") == "true", page)
+	if err != nil {
+		respondPageError(c, err, "Failed to retrieve notifications")
+		return
+	}
+
+	c.JSON(http.StatusOK, notifications)
+}
+
+// MarkNotificationReadHandler marks one notification as read
+func MarkNotificationReadHandler(c *gin.Context) {
+	userID, err := helpers.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	notificationID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+		return
+	}
+
+	err = db.MarkNotificationRead(c, userID, notificationID)
+	if errors.Is(err, db.ErrNotificationNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Notification marked as read"})
+}
+
+// MarkAllNotificationsReadHandler marks all of the current user's notifications as read
+func MarkAllNotificationsReadHandler(c *gin.Context) {
+	userID, err := helpers.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	if err := db.MarkAllNotificationsRead(c, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "All notifications marked as read"})
+}
@@ -0,0 +1,184 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"Online-Auction-System/backend/internal/db"
+	"Online-Auction-System/backend/internal/helpers"
+	"Online-Auction-System/backend/internal/schema"
+)
+
+const (
+	defaultOfferHours = 48
+	maxOfferHours     = 7 * 24
+)
+
+// CreateSecondChanceOfferHandler lets the seller of an unsold auction offer the
+// item to the next bidder in line at that bidder's highest bid
+func CreateSecondChanceOfferHandler(c *gin.Context) {
+	userID, err := helpers.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	auctionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid auction ID"})
+		return
+	}
+
+	var request schema.SecondChanceOfferRequest
+	if err := c.ShouldBindJSON(&request); err != nil && c.Request.ContentLength > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+
+	hours := request.ExpiresInHours
+	if hours == 0 {
+		hours = defaultOfferHours
+	}
+	if hours < 1 || hours > maxOfferHours {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Offers must expire within 1 to 168 hours"})
+		return
+	}
+
+	auction, err := db.GetAuctionByID(c, auctionID, userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Auction not found"})
+		return
+	}
+
+	if auction.SellerID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the seller can make a second-chance offer"})
+		return
+	}
+
+	offer, err := db.CreateSecondChanceOffer(c, auctionID, time.Now().Add(time.Duration(hours)*time.Hour))
+	if errors.Is(err, db.ErrAuctionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Auction not found"})
+		return
+	}
+	if errors.Is(err, db.ErrNoSecondChance) {
+		c.JSON(http.StatusConflict, gin.H{"error": "No second-chance offer can be made for this auction"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create offer"})
+		return
+	}
+
+	buyerEmail, _ := db.GetUserEmail(c, offer.BuyerID)
+	if buyerEmail != "" {
+		go func() {
+			additionalData := map[string]interface{}{
+				"username":     offer.BuyerName,
+				"offer_amount": offer.Amount,
+				"expires_at":   offer.ExpiresAt.Format("2006-01-02 15:04"),
+			}
+
+			helpers.SendAuctionEmail(c, buyerEmail, helpers.NotificationSecondChance, auctionID, additionalData)
+		}()
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"offer":   offer,
+		"message": "Second-chance offer sent",
+	})
+}
+
+// GetSecondChanceOffersHandler lists the second-chance offers made to the current user
+func GetSecondChanceOffersHandler(c *gin.Context) {
+	userID, err := helpers.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	offers, err := db.GetSecondChanceOffers(c, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve offers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, offers)
+}
+
+// AcceptSecondChanceOfferHandler buys the item at the offered price
+func AcceptSecondChanceOfferHandler(c *gin.Context) {
+	respondToSecondChanceOffer(c, true)
+}
+
+// DeclineSecondChanceOfferHandler turns the offer down
+func DeclineSecondChanceOfferHandler(c *gin.Context) {
+	respondToSecondChanceOffer(c, false)
+}
+
+func respondToSecondChanceOffer(c *gin.Context, accept bool) {
+	userID, err := helpers.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	offerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offer ID"})
+		return
+	}
+
+	offer, transactionID, err := db.RespondToSecondChanceOffer(c, offerID, userID, accept)
+	if errors.Is(err, db.ErrOfferNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Offer not found"})
+		return
+	}
+	if errors.Is(err, db.ErrOfferClosed) {
+		c.JSON(http.StatusConflict, gin.H{"error": "This offer has expired or was already answered"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to respond to offer"})
+		return
+	}
+
+	auction := publicAuction(c, offer.AuctionID)
+	sellerEmail, _ := db.GetUserEmail(c, auction.SellerID)
+	if sellerEmail != "" {
+		go func() {
+			additionalData := map[string]interface{}{
+				"is_seller":    true,
+				"username":     auction.SellerName,
+				"buyer_name":   offer.BuyerName,
+				"offer_amount": offer.Amount,
+				"offer_status": offer.Status,
+			}
+
+			helpers.SendAuctionEmail(c, sellerEmail, helpers.NotificationSecondChance, offer.AuctionID, additionalData)
+		}()
+	}
+
+	if !accept {
+		c.JSON(http.StatusOK, gin.H{
+			"offer":   offer,
+			"message": "Offer declined",
+		})
+		return
+	}
+
+	if wsManager != nil {
+		wsManager.BroadcastAuctionStatus(offer.AuctionID, "closed", db.CloseReasonSold, auction)
+	}
+
+	requestPayment(c, offer.AuctionID, transactionID, userID)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"offer":          offer,
+		"transaction_id": transactionID,
+		"message":        "Offer accepted, item purchased successfully",
+	})
+}
@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"Online-Auction-System/backend/internal/db"
+	"Online-Auction-System/backend/internal/schema"
+)
+
+// pageRequest reads the cursor, limit, sort and status query parameters of a
+// list endpoint. It writes an error response and returns false if they are invalid.
+func pageRequest(c *gin.Context) (schema.PageRequest, bool) {
+	page := schema.PageRequest{
+		Cursor: c.Query("cursor"),
+		Sort:   c.Query("sort"),
+		Status: c.Query("status"),
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		var err error
+		page.Limit, err = strconv.Atoi(limit)
+		if err != nil || page.Limit < 1 || page.Limit > db.MaxPageSize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Limit must be between 1 and 100"})
+			return page, false
+		}
+	}
+
+	switch page.Status {
+	case "", "scheduled", "open", "closed":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Status must be scheduled, open or closed"})
+		return page, false
+	}
+
+	return page, true
+}
+
+// respondPageError writes the response for an error from a paginated query
+func respondPageError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, db.ErrInvalidSort):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sort"})
+	case errors.Is(err, db.ErrInvalidCursor):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
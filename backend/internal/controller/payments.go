@@ -0,0 +1,267 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"Online-Auction-System/backend/config"
+	"Online-Auction-System/backend/internal/db"
+	"Online-Auction-System/backend/internal/helpers"
+	"Online-Auction-System/backend/internal/payments"
+	"Online-Auction-System/backend/internal/schema"
+)
+
+var paymentProvider payments.Provider
+
+func SetPaymentProvider(provider payments.Provider) {
+	paymentProvider = provider
+}
+
+// GetPaymentsHandler lists the payments the current user owes or has made
+func GetPaymentsHandler(c *gin.Context) {
+	userID, err := helpers.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	page, ok := pageRequest(c)
+	if !ok {
+		return
+	}
+
+	history, err := db.GetPayments(c, userID, page)
+	if err != nil {
+		respondPageError(c, err, "Failed to retrieve payments")
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// GetPaymentHandler shows one of the current user's payments
+func GetPaymentHandler(c *gin.Context) {
+	payment, ok := buyerPayment(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, payment)
+}
+
+// ChoosePaymentMethodHandler sets how the buyer will pay and opens a charge
+// with the payment provider for it. Choosing again replaces the charge.
+func ChoosePaymentMethodHandler(c *gin.Context) {
+	payment, ok := buyerPayment(c)
+	if !ok {
+		return
+	}
+
+	var request schema.PaymentMethodRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+
+	if !payments.ValidMethod(request.Method) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Payment method must be credit_card, UPI or bank_transfer"})
+		return
+	}
+
+	if !payablePayment(c, payment) {
+		return
+	}
+
+	userID, _ := helpers.GetUserID(c)
+	reference, err := paymentProvider.CreateCharge(c, payments.Charge{
+		PaymentID: payment.PaymentID,
+		BuyerID:   userID,
+		Amount:    payment.Amount,
+		Method:    request.Method,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to start the payment"})
+		return
+	}
+
+	err = db.SetPaymentMethod(c, payment.PaymentID, request.Method, paymentProvider.Name(), reference)
+	if errors.Is(err, db.ErrPaymentClosed) {
+		c.JSON(http.StatusConflict, gin.H{"error": "This payment is no longer pending"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set payment method"})
+		return
+	}
+
+	payment.Method = &request.Method
+	payment.ProviderReference = &reference
+	c.JSON(http.StatusOK, gin.H{
+		"payment": payment,
+		"message": "Payment method set",
+	})
+}
+
+// ConfirmPaymentHandler checks with the payment provider that the buyer has
+// paid and, if so, completes the payment
+func ConfirmPaymentHandler(c *gin.Context) {
+	payment, ok := buyerPayment(c)
+	if !ok {
+		return
+	}
+
+	if !payablePayment(c, payment) {
+		return
+	}
+
+	if payment.ProviderReference == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Choose a payment method first"})
+		return
+	}
+
+	err := paymentProvider.ConfirmCharge(c, *payment.ProviderReference)
+	if errors.Is(err, payments.ErrChargeDeclined) {
+		c.JSON(http.StatusPaymentRequired, gin.H{"error": "The payment has not been received"})
+		return
+	}
+	if errors.Is(err, payments.ErrUnknownCharge) {
+		c.JSON(http.StatusConflict, gin.H{"error": "The payment could not be found, please choose a payment method again"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to confirm the payment"})
+		return
+	}
+
+	deliveryID, err := db.CompletePayment(c, payment.PaymentID)
+	if errors.Is(err, db.ErrPaymentClosed) {
+		c.JSON(http.StatusConflict, gin.H{"error": "This payment is no longer pending"})
+		return
+	}
+	if errors.Is(err, db.ErrPaymentOverdue) {
+		c.JSON(http.StatusConflict, gin.H{"error": "The payment deadline has passed"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to complete payment"})
+		return
+	}
+
+	if deliveryID > 0 {
+		notifyDeliveryCreated(c, deliveryID)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Payment completed successfully"})
+}
+
+// payablePayment checks that a payment is pending and not past its deadline.
+// It writes an error response and returns false otherwise.
+func payablePayment(c *gin.Context, payment schema.PaymentResponse) bool {
+	if payment.Status != db.PaymentPending {
+		c.JSON(http.StatusConflict, gin.H{"error": "This payment is no longer pending"})
+		return false
+	}
+	if !payment.DueAt.After(time.Now()) {
+		c.JSON(http.StatusConflict, gin.H{"error": "The payment deadline has passed"})
+		return false
+	}
+	return true
+}
+
+// buyerPayment loads the payment named in the URL if it belongs to the
+// current user. It writes an error response and returns false otherwise.
+func buyerPayment(c *gin.Context) (schema.PaymentResponse, bool) {
+	userID, err := helpers.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return schema.PaymentResponse{}, false
+	}
+
+	paymentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payment ID"})
+		return schema.PaymentResponse{}, false
+	}
+
+	payment, err := db.GetPayment(c, paymentID, userID)
+	if errors.Is(err, db.ErrPaymentNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Payment not found"})
+		return payment, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve payment"})
+		return payment, false
+	}
+
+	return payment, true
+}
+
+// requestPayment asks the buyer of a new transaction to pay for it by the
+// payment deadline and emails them the request
+func requestPayment(c *gin.Context, auctionID, transactionID, buyerID int) {
+	request, err := db.CreatePaymentRequest(c, transactionID, config.PaymentWindow(), config.EscrowThreshold())
+	if err != nil {
+		fmt.Printf("Failed to request payment for transaction %d: %v\n", transactionID, err)
+		return
+	}
+	if request.PaymentID == 0 {
+		return
+	}
+
+	buyerEmail, _ := db.GetUserEmail(c, buyerID)
+	if buyerEmail == "" {
+		return
+	}
+	buyerName, _ := db.GetUserName(c, buyerID)
+
+	go func() {
+		additionalData := map[string]interface{}{
+			"username":   buyerName,
+			"payment_id": request.PaymentID,
+			"amount":     request.Amount,
+			"due_at":     request.DueAt.Format("2006-01-02 15:04"),
+		}
+
+		helpers.SendAuctionEmail(c, buyerEmail, helpers.NotificationPaymentRequest, auctionID, additionalData)
+	}()
+}
+
+// failUnpaidPayments fails the payments whose deadline has passed and emails
+// both sides of each unpaid sale
+func failUnpaidPayments(c *gin.Context) {
+	unpaid, err := db.FailOverduePayments(c)
+	if err != nil {
+		fmt.Printf("Failed to fail overdue payments: %v\n", err)
+		return
+	}
+
+	for _, transaction := range unpaid {
+		buyerEmail, _ := db.GetUserEmail(c, transaction.BuyerID)
+		buyerName, _ := db.GetUserName(c, transaction.BuyerID)
+		sellerEmail, _ := db.GetUserEmail(c, transaction.SellerID)
+		sellerName, _ := db.GetUserName(c, transaction.SellerID)
+
+		go func(transaction db.UnpaidTransaction) {
+			if buyerEmail != "" {
+				helpers.SendAuctionEmail(c, buyerEmail, helpers.NotificationPaymentFailed, transaction.AuctionID, map[string]interface{}{
+					"username": buyerName,
+					"amount":   transaction.Amount,
+				})
+			}
+
+			if sellerEmail != "" {
+				helpers.SendAuctionEmail(c, sellerEmail, helpers.NotificationPaymentFailed, transaction.AuctionID, map[string]interface{}{
+					"is_seller":  true,
+					"username":   sellerName,
+					"buyer_name": buyerName,
+					"amount":     transaction.Amount,
+				})
+			}
+		}(transaction)
+	}
+}
@@ -101,9 +101,14 @@ func GetUserBidsHandler(c *gin.Context) {
 		return
 	}
 
-	bids, err := db.GetUserBids(c, userID)
+	page, ok := pageRequest(c)
+	if !ok {
+		return
+	}
+
+	bids, err := db.GetUserBids(c, userID, page)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user bids"})
+		respondPageError(c, err, "Failed to retrieve user bids")
 		return
 	}
 
@@ -118,9 +123,14 @@ func GetUserSoldHandler(c *gin.Context) {
 		return
 	}
 
-	soldItems, err := db.GetSoldItems(c, userID)
+	page, ok := pageRequest(c)
+	if !ok {
+		return
+	}
+
+	soldItems, err := db.GetSoldItems(c, userID, page)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve sold items"})
+		respondPageError(c, err, "Failed to retrieve sold items")
 		return
 	}
 
@@ -135,9 +145,14 @@ func GetUserBoughtHandler(c *gin.Context) {
 		return
 	}
 
-	boughtItems, err := db.GetBoughtItems(c, userID)
+	page, ok := pageRequest(c)
+	if !ok {
+		return
+	}
+
+	boughtItems, err := db.GetBoughtItems(c, userID, page)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve bought items"})
+		respondPageError(c, err, "Failed to retrieve bought items")
 		return
 	}
 
@@ -0,0 +1,216 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"Online-Auction-System/backend/internal/db"
+	"Online-Auction-System/backend/internal/helpers"
+	"Online-Auction-System/backend/internal/schema"
+)
+
+const maxSavedSearches = 20
+
+// GetSavedSearchesHandler lists the current user's saved searches
+func GetSavedSearchesHandler(c *gin.Context) {
+	userID, err := helpers.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	searches, err := db.GetSavedSearches(c, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve saved searches"})
+		return
+	}
+
+	c.JSON(http.StatusOK, searches)
+}
+
+// CreateSavedSearchHandler saves a search the current user wants alerts for
+func CreateSavedSearchHandler(c *gin.Context) {
+	userID, err := helpers.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	request, ok := savedSearchRequest(c)
+	if !ok {
+		return
+	}
+
+	count, err := db.CountSavedSearches(c, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save search"})
+		return
+	}
+	if count >= maxSavedSearches {
+		c.JSON(http.StatusConflict, gin.H{"error": "You can save at most 20 searches"})
+		return
+	}
+
+	search, err := db.CreateSavedSearch(c, userID, request)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save search"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"search":  search,
+		"message": "Search saved successfully",
+	})
+}
+
+// UpdateSavedSearchHandler changes a saved search or how its alerts are delivered
+func UpdateSavedSearchHandler(c *gin.Context) {
+	userID, err := helpers.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	searchID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid search ID"})
+		return
+	}
+
+	request, ok := savedSearchRequest(c)
+	if !ok {
+		return
+	}
+
+	search, err := db.UpdateSavedSearch(c, userID, searchID, request)
+	if errors.Is(err, db.ErrSavedSearchNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Saved search not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update saved search"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"search":  search,
+		"message": "Saved search updated successfully",
+	})
+}
+
+// DeleteSavedSearchHandler removes one of the current user's saved searches
+func DeleteSavedSearchHandler(c *gin.Context) {
+	userID, err := helpers.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	searchID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid search ID"})
+		return
+	}
+
+	err = db.DeleteSavedSearch(c, userID, searchID)
+	if errors.Is(err, db.ErrSavedSearchNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Saved search not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete saved search"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Saved search deleted successfully"})
+}
+
+// savedSearchRequest binds and validates a saved search. It writes an error
+// response and returns false if the request is invalid.
+func savedSearchRequest(c *gin.Context) (schema.SavedSearchRequest, bool) {
+	var request schema.SavedSearchRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return request, false
+	}
+
+	request.Name = strings.TrimSpace(request.Name)
+	request.Keywords = strings.TrimSpace(request.Keywords)
+	if request.Name == "" || len(request.Name) > 100 || len(request.Keywords) > 255 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Name must be 1 to 100 characters and keywords at most 255"})
+		return request, false
+	}
+
+	if request.MaxPrice < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Maximum price cannot be negative"})
+		return request, false
+	}
+
+	if request.Keywords == "" && request.CategoryID == nil && request.MaxPrice == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A saved search needs keywords, a category or a maximum price"})
+		return request, false
+	}
+
+	switch request.Delivery {
+	case "":
+		request.Delivery = db.AlertInApp
+	case db.AlertInApp, db.AlertDailyDigest:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Alert delivery must be in_app or daily_digest"})
+		return request, false
+	}
+
+	if request.CategoryID != nil {
+		exists, err := db.CategoryExists(c, *request.CategoryID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check category"})
+			return request, false
+		}
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Category not found"})
+			return request, false
+		}
+	}
+
+	return request, true
+}
+
+// alertSavedSearches tells the users whose saved searches match a newly opened auction
+func alertSavedSearches(c *gin.Context, auctionID int) {
+	if err := db.MatchSavedSearches(c, auctionID); err != nil {
+		fmt.Printf("Failed to match auction %d against saved searches: %v\n", auctionID, err)
+	}
+}
+
+// sendSavedSearchDigests emails the daily digests of saved-search matches that are due
+func sendSavedSearchDigests(c *gin.Context) {
+	digests, err := db.ClaimSavedSearchDigests(c)
+	if err != nil {
+		fmt.Printf("Failed to get saved-search digests: %v\n", err)
+		return
+	}
+
+	for _, digest := range digests {
+		go func(digest db.SearchDigest) {
+			matches := make([]map[string]interface{}, len(digest.Matches))
+			for i, match := range digest.Matches {
+				matches[i] = map[string]interface{}{
+					"auction_id":  match.AuctionID,
+					"title":       match.Title,
+					"search_name": match.SearchName,
+					"end_time":    match.EndTime.Format("2006-01-02 15:04"),
+				}
+			}
+
+			helpers.SendDigestEmail(digest.Email, map[string]interface{}{
+				"username": digest.Username,
+				"matches":  matches,
+			})
+		}(digest)
+	}
+}
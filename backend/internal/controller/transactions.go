@@ -1,7 +1,10 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 
@@ -10,9 +13,13 @@ import (
 	"Online-Auction-System/backend/internal/schema"
 )
 
-// SubmitReviewHandler handles submitting a review for an auction
+const maxReviewLength = 2000
+
+// SubmitReviewHandler handles submitting a review for an auction. The buyer
+// reviews the seller and the seller reviews the buyer, once each; submitting
+// again edits the review until its edit window closes.
 func SubmitReviewHandler(c *gin.Context) {
-	_, err := helpers.GetUserID(c)
+	userID, err := helpers.GetUserID(c)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
 		return
@@ -24,17 +31,119 @@ func SubmitReviewHandler(c *gin.Context) {
 		return
 	}
 
-	transactionID, err := db.GetTransactionByAuctionID(c, request.AuctionID)
+	if request.Rating < 1 || request.Rating > 5 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Rating must be between 1 and 5"})
+		return
+	}
+
+	request.Comment = strings.TrimSpace(request.Comment)
+	if request.Comment == "" || len(request.Comment) > maxReviewLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Comment must be 1 to 2000 characters"})
+		return
+	}
+
+	review, err := db.GetReviewableTransaction(c, request.AuctionID, userID, request.BuyerID)
+	if errors.Is(err, db.ErrNotTransactionParty) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the buyer or seller of this auction can review it"})
+		return
+	}
+	if errors.Is(err, db.ErrBuyerRequired) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Choose which buyer you are reviewing"})
+		return
+	}
+	if errors.Is(err, db.ErrSaleNotPaid) {
+		c.JSON(http.StatusConflict, gin.H{"error": "A sale can only be reviewed once it has been paid for"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find the transaction"})
+		return
+	}
+
+	review.Rating = request.Rating
+	review.Comment = request.Comment
+	reviewID, err := db.SubmitReview(c, review)
+	if errors.Is(err, db.ErrReviewLocked) {
+		c.JSON(http.StatusConflict, gin.H{"error": "This review can no longer be edited"})
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "No transaction found for this auction"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to submit review"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"review_id": reviewID,
+		"message":   "Review submitted successfully",
+	})
+}
+
+// ReplyToReviewHandler posts the reviewed user's one public reply to a review
+func ReplyToReviewHandler(c *gin.Context) {
+	userID, err := helpers.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	reviewID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid review ID"})
+		return
+	}
+
+	var request schema.ReviewReplyRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+
+	request.Reply = strings.TrimSpace(request.Reply)
+	if request.Reply == "" || len(request.Reply) > maxReviewLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Reply must be 1 to 2000 characters"})
+		return
+	}
+
+	err = db.ReplyToReview(c, reviewID, userID, request.Reply)
+	switch {
+	case errors.Is(err, db.ErrReviewNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Review not found"})
+	case errors.Is(err, db.ErrNotReviewee):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the reviewed user can reply to a review"})
+	case errors.Is(err, db.ErrAlreadyReplied):
+		c.JSON(http.StatusConflict, gin.H{"error": "This review already has a reply"})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to post reply"})
+	default:
+		c.JSON(http.StatusOK, gin.H{"message": "Reply posted successfully"})
+	}
+}
+
+// GetUserReviewsHandler lists the reviews written about a user. ?as=seller or
+// ?as=buyer keeps only those they received in that role.
+func GetUserReviewsHandler(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	role := c.Query("as")
+	if role != "" && role != db.RoleSeller && role != db.RoleBuyer {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "as must be seller or buyer"})
+		return
+	}
+
+	page, ok := pageRequest(c)
+	if !ok {
 		return
 	}
 
-	err = db.SubmitReview(c, transactionID, request.Rating)
+	reviews, err := db.GetUserReviews(c, userID, role, page)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err})
+		respondPageError(c, err, "Failed to retrieve reviews")
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Review submitted successfully"})
+	c.JSON(http.StatusOK, reviews)
 }
@@ -0,0 +1,165 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"Online-Auction-System/backend/internal/db"
+	"Online-Auction-System/backend/internal/helpers"
+	"Online-Auction-System/backend/internal/schema"
+)
+
+// GetPublicProfileHandler shows a seller's public page: their rating,
+// followers and a page of their open and scheduled auctions. Contact details
+// are never included.
+func GetPublicProfileHandler(c *gin.Context) {
+	viewerID, err := helpers.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	page, ok := pageRequest(c)
+	if !ok {
+		return
+	}
+
+	profile, err := db.GetPublicProfile(c, viewerID, userID)
+	if errors.Is(err, db.ErrUserNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user"})
+		return
+	}
+
+	profile.ActiveListings, err = db.GetSellerListings(c, viewerID, userID, page)
+	if err != nil {
+		respondPageError(c, err, "Failed to retrieve listings")
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// FollowSellerHandler follows a seller, or changes how the current user hears
+// about their new listings
+func FollowSellerHandler(c *gin.Context) {
+	userID, err := helpers.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	sellerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if sellerID == userID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot follow yourself"})
+		return
+	}
+
+	var request schema.FollowRequest
+	if err := c.ShouldBindJSON(&request); err != nil && c.Request.ContentLength > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request payload"})
+		return
+	}
+
+	switch request.Delivery {
+	case "":
+		request.Delivery = db.AlertInApp
+	case db.AlertInApp, db.AlertEmail:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Alert delivery must be in_app or email"})
+		return
+	}
+
+	if _, err := db.GetUserByID(c, sellerID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if err := db.FollowSeller(c, userID, sellerID, request.Delivery); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to follow seller"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "You are now following this seller"})
+}
+
+// UnfollowSellerHandler stops the current user following a seller
+func UnfollowSellerHandler(c *gin.Context) {
+	userID, err := helpers.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	sellerID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	err = db.UnfollowSeller(c, userID, sellerID)
+	if errors.Is(err, db.ErrNotFollowing) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "You are not following this seller"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unfollow seller"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Seller unfollowed"})
+}
+
+// GetFollowedSellersHandler lists the sellers the current user follows
+func GetFollowedSellersHandler(c *gin.Context) {
+	userID, err := helpers.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	sellers, err := db.GetFollowedSellers(c, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve followed sellers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, sellers)
+}
+
+// notifyFollowers fans a newly opened auction out to its seller's followers
+func notifyFollowers(c *gin.Context, auctionID int) {
+	alerts, err := db.NotifyFollowers(c, auctionID)
+	if err != nil {
+		fmt.Printf("Failed to notify followers of auction %d: %v\n", auctionID, err)
+		return
+	}
+
+	for _, alert := range alerts {
+		go func(alert db.FollowerAlert) {
+			additionalData := map[string]interface{}{
+				"username": alert.Username,
+			}
+
+			helpers.SendAuctionEmail(c, alert.Email, helpers.NotificationNewListing, auctionID, additionalData)
+		}(alert)
+	}
+}
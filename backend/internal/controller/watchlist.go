@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"Online-Auction-System/backend/config"
+	"Online-Auction-System/backend/internal/db"
+	"Online-Auction-System/backend/internal/helpers"
+)
+
+// GetWatchlistHandler lists the auctions the current user is watching
+func GetWatchlistHandler(c *gin.Context) {
+	userID, err := helpers.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	page, ok := pageRequest(c)
+	if !ok {
+		return
+	}
+
+	auctions, err := db.GetWatchlist(c, userID, page)
+	if err != nil {
+		respondPageError(c, err, "Failed to retrieve watchlist")
+		return
+	}
+
+	c.JSON(http.StatusOK, auctions)
+}
+
+// AddToWatchlistHandler stars an auction for the current user
+func AddToWatchlistHandler(c *gin.Context) {
+	userID, err := helpers.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	auctionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid auction ID"})
+		return
+	}
+
+	auction, err := db.GetAuctionByID(c, auctionID, userID)
+	if err != nil || auction.Status == "draft" || auction.Status == "deleted" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Auction not found"})
+		return
+	}
+
+	if auction.SellerID == userID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot watch your own auction"})
+		return
+	}
+
+	if err := db.AddToWatchlist(c, userID, auctionID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add auction to watchlist"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Auction added to watchlist"})
+}
+
+// RemoveFromWatchlistHandler unstars an auction for the current user
+func RemoveFromWatchlistHandler(c *gin.Context) {
+	userID, err := helpers.GetUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	auctionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid auction ID"})
+		return
+	}
+
+	err = db.RemoveFromWatchlist(c, userID, auctionID)
+	if errors.Is(err, db.ErrNotWatching) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Auction is not on your watchlist"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove auction from watchlist"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Auction removed from watchlist"})
+}
+
+// sendEndingSoonReminders emails the watchers of auctions that are about to end
+func sendEndingSoonReminders(c *gin.Context) {
+	lead := config.EndingSoonLead()
+	reminders, err := db.ClaimEndingSoonReminders(c, lead)
+	if err != nil {
+		fmt.Printf("Failed to get ending-soon reminders: %v\n", err)
+		return
+	}
+
+	for _, reminder := range reminders {
+		go func(reminder db.EndingSoonReminder) {
+			additionalData := map[string]interface{}{
+				"username":     reminder.Username,
+				"lead_minutes": int(lead.Minutes()),
+			}
+
+			helpers.SendAuctionEmail(c, reminder.Email, helpers.NotificationEndingSoon, reminder.AuctionID, additionalData)
+		}(reminder)
+	}
+}
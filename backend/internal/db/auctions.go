@@ -3,37 +3,147 @@ package db
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+
 	"Online-Auction-System/backend/config"
 	"Online-Auction-System/backend/internal/schema"
 )
 
-// CreateItem inserts a new item
-func CreateItem(c context.Context, sellerID int, title, description string, startingBid float64, imagePath string) (int, error) {
+// Auction formats
+const (
+	AuctionTypeEnglish           = "english"
+	AuctionTypeSealedFirstPrice  = "sealed_first_price"
+	AuctionTypeSealedSecondPrice = "sealed_second_price"
+	AuctionTypeDutch             = "dutch"
+)
+
+// IsSealed reports whether bids in an auction of this type stay hidden until it closes
+func IsSealed(auctionType string) bool {
+	return auctionType == AuctionTypeSealedFirstPrice || auctionType == AuctionTypeSealedSecondPrice
+}
+
+// maskSealedBids hides the standing price of a sealed-bid auction that has not closed yet
+func maskSealedBids(auction *schema.AuctionResponse) {
+	if !IsSealed(auction.AuctionType) || auction.Status == "closed" {
+		return
+	}
+	auction.CurrentHighestBid = 0
+	auction.IsHighestBidder = false
+	auction.ReserveMet = false
+	auction.NextMinBid = auction.StartingBid
+}
+
+// Reasons recorded when an auction closes
+const (
+	CloseReasonSold          = "sold"
+	CloseReasonNoBids        = "no_bids"
+	CloseReasonReserveNotMet = "reserve_not_met"
+)
+
+// CreateAuction puts a new item up for auction: it inserts the item with its
+// category, tags and lot pieces, then the auction with its increments,
+// soft-close, Dutch and relisting settings. Everything is written in one
+// transaction, so a failure leaves no half-configured auction behind. The
+// request must already be validated, with its auction type, quantity and tags
+// normalized. It returns the new item and auction IDs.
+func CreateAuction(c context.Context, sellerID int, request schema.ItemAuctionRequest, increments []schema.BidIncrementTier) (int, int, error) {
+	tx, err := config.DB.Begin(c)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback(c)
+
+	itemID, err := createItem(c, tx, sellerID, request)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if request.CategoryID != 0 {
+		if err := setItemCategory(c, tx, itemID, request.CategoryID); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if err := setItemTags(c, tx, itemID, request.Tags); err != nil {
+		return 0, 0, err
+	}
+
+	if err := setLotItems(c, tx, itemID, request.LotItems); err != nil {
+		return 0, 0, err
+	}
+
+	auctionID, err := createAuction(c, tx, itemID, request.AuctionType, request.StartTime, request.EndTime, request.Draft)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if len(increments) > 0 {
+		if err := setBidIncrements(c, tx, auctionID, increments); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if request.SoftClose.WindowMinutes > 0 {
+		if err := setSoftClose(c, tx, auctionID, request.SoftClose); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if request.AuctionType == AuctionTypeDutch {
+		if err := setDutchSchedule(c, tx, auctionID, request.Dutch); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if request.AutoRelist > 0 {
+		if err := setAutoRelist(c, tx, auctionID, request.AutoRelist); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	return itemID, auctionID, tx.Commit(c)
+}
+
+// createItem inserts a new item offering quantity identical units. A reserve
+// price or buy now price of 0 means the item has none.
+func createItem(c context.Context, tx pgx.Tx, sellerID int, request schema.ItemAuctionRequest) (int, error) {
 	var itemID int
-	err := config.DB.QueryRow(c,
-		"INSERT INTO items (seller_id, title, description, starting_bid, image_path) VALUES ($1, $2, $3, $4, $5) RETURNING item_id",
-		sellerID, title, description, startingBid, imagePath).Scan(&itemID)
+	err := tx.QueryRow(c,
+		"INSERT INTO items (seller_id, title, description, starting_bid, quantity, reserve_price, buy_now_price, image_path) VALUES ($1, $2, $3, $4, $5, NULLIF($6, 0), NULLIF($7, 0), $8) RETURNING item_id",
+		sellerID, request.Title, request.Description, request.StartingBid, request.Quantity,
+		request.ReservePrice, request.BuyNowPrice, request.ImagePath).Scan(&itemID)
 	return itemID, err
 }
 
-// CreateAuction creates a new auction for an item
-func CreateAuction(c context.Context, itemID int, startTime, endTime time.Time) (int, error) {
+// scheduledStatus returns the status of a published auction: open once
+// startTime has passed, scheduled until then
+func scheduledStatus(startTime time.Time) string {
+	if startTime.After(time.Now()) {
+		return "scheduled"
+	}
+	return "open"
+}
+
+// createAuction creates a new auction for an item. A draft stays hidden from
+// other users until it is published.
+func createAuction(c context.Context, tx pgx.Tx, itemID int, auctionType string, startTime, endTime time.Time, draft bool) (int, error) {
 	var auctionID int
-	auctionStatus := "open"
+	auctionStatus := scheduledStatus(startTime)
 
-	if startTime.After(time.Now()) {
-		auctionStatus = "closed"
+	if draft {
+		auctionStatus = "draft"
 	}
 
-	err := config.DB.QueryRow(c,
-		"INSERT INTO auctions (item_id, start_time, end_time, auction_status) VALUES ($1, $2, $3, $4) RETURNING auction_id",
-		itemID, startTime, endTime, auctionStatus).Scan(&auctionID)
+	err := tx.QueryRow(c,
+		"INSERT INTO auctions (item_id, auction_type, start_time, end_time, auction_status) VALUES ($1, $2, $3, $4, $5) RETURNING auction_id",
+		itemID, auctionType, startTime, endTime, auctionStatus).Scan(&auctionID)
 
 	if err == nil {
-		_, err = config.DB.Exec(c,
+		_, err = tx.Exec(c,
 			`INSERT INTO auction_participants (auction_id, user_id, user_role) 
              SELECT $1, seller_id, 'seller' FROM items WHERE item_id = $2`,
 			auctionID, itemID)
@@ -42,41 +152,138 @@ func CreateAuction(c context.Context, itemID int, startTime, endTime time.Time)
 	return auctionID, err
 }
 
-// GetAuctions retrieves a list of active auctions
-func GetAuctions(c context.Context) ([]schema.AuctionResponse, error) {
-	rows, err := config.DB.Query(c, `
-        SELECT a.auction_id, a.item_id, i.title, i.description, 
-               i.starting_bid, COALESCE(i.current_highest_bid, 0), 
-               i.seller_id, u.username, 
-               a.start_time, a.end_time, a.auction_status, i.image_path
-        FROM auctions a
-        JOIN items i ON a.item_id = i.item_id
-        JOIN users u ON i.seller_id = u.user_id
-        ORDER BY a.end_time ASC`)
+// setSoftClose stores the soft-close settings for an auction. A window of 0 disables soft close
+// and maxExtensions of 0 leaves the number of extensions uncapped.
+func setSoftClose(c context.Context, tx pgx.Tx, auctionID int, settings schema.SoftCloseSettings) error {
+	_, err := tx.Exec(c, `
+        UPDATE auctions
+        SET soft_close_window = NULLIF($2, 0),
+            soft_close_extension = NULLIF($3, 0),
+            soft_close_max_extensions = NULLIF($4, 0)
+        WHERE auction_id = $1
+    `, auctionID, settings.WindowMinutes, settings.ExtensionMinutes, settings.MaxExtensions)
+	return err
+}
 
-	if err != nil {
-		return nil, err
+// setAutoRelist sets how many times the auction is relisted automatically if it closes without bids
+func setAutoRelist(c context.Context, tx pgx.Tx, auctionID int, times int) error {
+	_, err := tx.Exec(c,
+		"UPDATE auctions SET auto_relist_remaining = $2 WHERE auction_id = $1",
+		auctionID, times)
+	return err
+}
+
+// reputationColumns are the cached reputation of a seller, left joined as sr
+const reputationColumns = `
+        COALESCE(sr.average_rating, 0)::float8, COALESCE(sr.review_count, 0),
+        sr.positive_percent_12m::float8, COALESCE(sr.completed_sales, 0)`
+
+// auctionHighestBid and auctionHighestBidder are an auction's own highest bid
+// and bidder. The item's copy moves on to the new auction when it is relisted,
+// so a relisted auction reads the result frozen onto it instead.
+const (
+	auctionHighestBid    = `(CASE WHEN a.relisted_at IS NULL THEN i.current_highest_bid ELSE a.final_price END)`
+	auctionHighestBidder = `(CASE WHEN a.relisted_at IS NULL THEN i.current_highest_bidder ELSE a.final_bidder END)`
+)
+
+// auctionListColumns are the columns of an auction in a list, read with
+// auctionListFields. Queries select them from auctions a, items i, users u
+// and seller_reputation sr.
+const auctionListColumns = `
+        a.auction_id, a.item_id, i.title, i.description,
+        i.starting_bid, COALESCE(`+auctionHighestBid+`, 0),
+        i.seller_id, u.username,`+reputationColumns+`,
+        a.start_time, a.end_time, a.auction_status, i.image_path,
+        i.reserve_price IS NOT NULL,
+        i.reserve_price IS NULL OR COALESCE(`+auctionHighestBid+`, 0) >= i.reserve_price,
+        COALESCE(a.close_reason, ''), a.auction_type, i.quantity,
+        (SELECT COUNT(*) FROM lot_items l WHERE l.item_id = i.item_id),
+        COALESCE(i.category_id, 0), ARRAY(SELECT tag FROM item_tags t WHERE t.item_id = i.item_id ORDER BY tag),
+        (SELECT COUNT(*) FROM watchlist w WHERE w.auction_id = a.auction_id)
+`
+
+func auctionListFields(auction *schema.AuctionResponse) []any {
+	return []any{
+		&auction.AuctionID, &auction.ItemID, &auction.Title, &auction.Description,
+		&auction.StartingBid, &auction.CurrentHighestBid, &auction.SellerID, &auction.SellerName,
+		&auction.SellerReputation.AverageRating, &auction.SellerReputation.ReviewCount,
+		&auction.SellerReputation.PositivePercent, &auction.SellerReputation.CompletedSales,
+		&auction.StartTime, &auction.EndTime, &auction.Status, &auction.ImagePath,
+		&auction.HasReserve, &auction.ReserveMet, &auction.CloseReason, &auction.AuctionType,
+		&auction.Quantity, &auction.LotSize, &auction.CategoryID, &auction.Tags,
+		&auction.Watchers,
 	}
-	defer rows.Close()
+}
 
-	var auctions []schema.AuctionResponse
-	for rows.Next() {
+// auctionSorts are the orders an auction list can be sorted in
+var auctionSorts = map[string]sortOrder{
+	SortEndingSoon: {key: "a.end_time", keyType: "timestamp", id: "a.auction_id"},
+	SortNewest:     {key: "a.auction_id", keyType: "integer", id: "a.auction_id", desc: true},
+	SortPriceAsc:   {key: searchPrice, keyType: "numeric", id: "a.auction_id"},
+	SortPriceDesc:  {key: searchPrice, keyType: "numeric", id: "a.auction_id", desc: true},
+	SortBidCount:   {key: "(SELECT COUNT(*) FROM bids b WHERE b.auction_id = a.auction_id)", keyType: "bigint", id: "a.auction_id", desc: true},
+}
+
+// GetAuctions retrieves a page of published auctions, ending soonest first
+// unless another sort is asked for, marking those userID is watching.
+// Filtering by category includes the auctions in all of its subcategories.
+func GetAuctions(c context.Context, userID int, filter schema.AuctionFilter, page schema.PageRequest) (schema.Page[schema.AuctionResponse], error) {
+	query := listQuery{
+		columns: auctionListColumns,
+		from: `
+        auctions a
+        JOIN items i ON a.item_id = i.item_id
+        JOIN users u ON i.seller_id = u.user_id
+        LEFT JOIN seller_reputation sr ON sr.seller_id = i.seller_id
+        WHERE a.auction_status != 'draft'
+        AND ($1 = '' OR a.auction_status = $1)
+        AND ($2 = 0 OR i.category_id IN (
+            WITH RECURSIVE subtree AS (
+                SELECT category_id FROM categories WHERE category_id = $2
+                UNION ALL
+                SELECT ch.category_id FROM categories ch JOIN subtree s ON ch.parent_id = s.category_id
+            )
+            SELECT category_id FROM subtree
+        ))
+        AND ($3 = '' OR EXISTS (SELECT 1 FROM item_tags t WHERE t.item_id = i.item_id AND t.tag = $3))`,
+		args:  []any{page.Status, filter.CategoryID, filter.Tag},
+		sorts: auctionSorts,
+		sort:  SortEndingSoon,
+	}
+
+	auctions, err := queryPage(c, query, page, func(rows pgx.Rows, extra ...any) (schema.AuctionResponse, error) {
 		var auction schema.AuctionResponse
-		err := rows.Scan(
-			&auction.AuctionID, &auction.ItemID, &auction.Title, &auction.Description,
-			&auction.StartingBid, &auction.CurrentHighestBid, &auction.SellerID, &auction.SellerName,
-			&auction.StartTime, &auction.EndTime, &auction.Status, &auction.ImagePath,
-		)
-		if err != nil {
-			return nil, err
+		if err := rows.Scan(append(auctionListFields(&auction), extra...)...); err != nil {
+			return auction, err
 		}
-		auctions = append(auctions, auction)
+		maskSealedBids(&auction)
+		return auction, nil
+	})
+	if err != nil {
+		return auctions, err
 	}
 
-	return auctions, rows.Err()
+	watching, err := watchedAuctions(c, userID, auctionIDs(auctions.Items))
+	if err != nil {
+		return auctions, err
+	}
+	for i := range auctions.Items {
+		auctions.Items[i].Watching = watching[auctions.Items[i].AuctionID]
+	}
+
+	return auctions, nil
 }
 
-// GetAuctionByID retrieves details of a specific auction
+func auctionIDs(auctions []schema.AuctionResponse) []int {
+	ids := make([]int, len(auctions))
+	for i, auction := range auctions {
+		ids[i] = auction.AuctionID
+	}
+	return ids
+}
+
+// GetAuctionByID retrieves details of a specific auction. The reserve price is
+// only included when userID is the seller; everyone else just sees whether it is met.
 func GetAuctionByID(c context.Context, auctionID int, userID int) (schema.AuctionResponse, error) {
 	var auction schema.AuctionResponse
 	var currentUserBid sql.NullFloat64
@@ -84,18 +291,28 @@ func GetAuctionByID(c context.Context, auctionID int, userID int) (schema.Auctio
 
 	err := config.DB.QueryRow(c, `
         SELECT a.auction_id, a.item_id, i.title, i.description, i.starting_bid,
-            COALESCE(i.current_highest_bid, 0) as highest_bid, 
-            i.seller_id, u.username as seller_name,
+            COALESCE(`+auctionHighestBid+`, 0) as highest_bid,
+            i.seller_id, u.username as seller_name,`+reputationColumns+`,
             a.start_time, a.end_time, a.auction_status, i.image_path,
-            CASE WHEN i.current_highest_bidder = $2 THEN true ELSE false END as is_highest_bidder,
+            CASE WHEN `+auctionHighestBidder+` = $2 THEN true ELSE false END as is_highest_bidder,
             (SELECT NULLIF(MAX(bid_amount), 0) FROM bids WHERE auction_id = a.auction_id AND buyer_id = $2) as current_user_bid,
-            (SELECT NULLIF(bid_amount, 0) FROM automated_bids WHERE auction_id = a.auction_id AND buyer_id = $2) as current_automated_bid
+            (SELECT NULLIF(bid_amount, 0) FROM automated_bids WHERE auction_id = a.auction_id AND buyer_id = $2) as current_automated_bid,
+            COALESCE(i.reserve_price, 0), COALESCE(a.close_reason, ''), COALESCE(i.buy_now_price, 0),
+            COALESCE(a.soft_close_window, 0), COALESCE(a.soft_close_extension, 0),
+            COALESCE(a.soft_close_max_extensions, 0), a.extension_count, a.auction_type,
+            COALESCE(a.dutch_floor_price, 0), COALESCE(a.dutch_price_step, 0),
+            COALESCE(a.dutch_step_minutes, 0), COALESCE(a.dutch_current_price, 0),
+            i.quantity, a.auto_relist_remaining, COALESCE(a.relisted_from, 0),
+            COALESCE(i.category_id, 0), ARRAY(SELECT tag FROM item_tags t WHERE t.item_id = i.item_id ORDER BY tag),
+            (SELECT COUNT(*) FROM watchlist w WHERE w.auction_id = a.auction_id),
+            EXISTS(SELECT 1 FROM watchlist w WHERE w.auction_id = a.auction_id AND w.user_id = $2)
         FROM auctions a
         JOIN items i ON a.item_id = i.item_id
         JOIN users u ON i.seller_id = u.user_id
+        LEFT JOIN seller_reputation sr ON sr.seller_id = i.seller_id
         LEFT JOIN bids b ON a.auction_id = b.auction_id
         WHERE a.auction_id = $1
-        GROUP BY a.auction_id, i.item_id, u.username, i.current_highest_bidder, i.highest_automated_bid
+        GROUP BY a.auction_id, i.item_id, u.username, i.current_highest_bidder, sr.seller_id
     `, auctionID, userID).Scan(
 		&auction.AuctionID,
 		&auction.ItemID,
@@ -105,6 +322,10 @@ func GetAuctionByID(c context.Context, auctionID int, userID int) (schema.Auctio
 		&auction.CurrentHighestBid,
 		&auction.SellerID,
 		&auction.SellerName,
+		&auction.SellerReputation.AverageRating,
+		&auction.SellerReputation.ReviewCount,
+		&auction.SellerReputation.PositivePercent,
+		&auction.SellerReputation.CompletedSales,
 		&auction.StartTime,
 		&auction.EndTime,
 		&auction.Status,
@@ -112,6 +333,25 @@ func GetAuctionByID(c context.Context, auctionID int, userID int) (schema.Auctio
 		&auction.IsHighestBidder,
 		&currentUserBid,
 		&currentAutomatedBid,
+		&auction.ReservePrice,
+		&auction.CloseReason,
+		&auction.BuyNowPrice,
+		&auction.SoftClose.WindowMinutes,
+		&auction.SoftClose.ExtensionMinutes,
+		&auction.SoftClose.MaxExtensions,
+		&auction.ExtensionCount,
+		&auction.AuctionType,
+		&auction.Dutch.FloorPrice,
+		&auction.Dutch.PriceStep,
+		&auction.Dutch.StepMinutes,
+		&auction.Dutch.CurrentPrice,
+		&auction.Quantity,
+		&auction.AutoRelist,
+		&auction.RelistedFrom,
+		&auction.CategoryID,
+		&auction.Tags,
+		&auction.Watchers,
+		&auction.Watching,
 	)
 
 	if currentUserBid.Valid {
@@ -122,73 +362,104 @@ func GetAuctionByID(c context.Context, auctionID int, userID int) (schema.Auctio
 		auction.CurrentAutomatedBid = currentAutomatedBid.Float64
 	}
 
-	return auction, err
-}
-
-// CreateBid adds a new bid to an auction
-func CreateBid(c context.Context, auctionID, buyerID int, amount float64) (int, error) {
-	tx, err := config.DB.Begin(c)
 	if err != nil {
-		return 0, err
+		return auction, err
 	}
-	defer tx.Rollback(c)
 
-	var bidID int
-	err = tx.QueryRow(c, `
-        INSERT INTO bids (auction_id, buyer_id, bid_amount) 
-        VALUES ($1, $2, $3) 
-        RETURNING bid_id`,
-		auctionID, buyerID, amount).Scan(&bidID)
+	auction.HasReserve = auction.ReservePrice > 0
+	auction.ReserveMet = !auction.HasReserve || auction.CurrentHighestBid >= auction.ReservePrice
+	if userID != auction.SellerID {
+		auction.ReservePrice = 0
+	}
 
-	if err != nil {
-		return 0, err
+	auction.BuyNowAvailable = auction.Status == "open" && BuyNowAvailable(auction.BuyNowPrice, auction.CurrentHighestBid)
+
+	auction.NextMinBid = auction.StartingBid
+	if auction.CurrentHighestBid > 0 {
+		increments, err := GetBidIncrements(c, auctionID)
+		if err != nil {
+			return auction, err
+		}
+		auction.NextMinBid = NextMinBid(increments, auction.CurrentHighestBid)
 	}
 
-	if err = tx.Commit(c); err != nil {
-		return 0, err
+	if auction.AuctionType == AuctionTypeDutch {
+		auction.NextMinBid = auction.Dutch.CurrentPrice
+	}
+
+	if auction.Quantity > 1 {
+		auction.NextMinBid, err = GetUnitNextMinBid(c, auction)
+		if err != nil {
+			return auction, err
+		}
+	}
+
+	auction.LotItems, err = GetLotItems(c, auction.ItemID)
+	if err != nil {
+		return auction, err
 	}
+	auction.LotSize = len(auction.LotItems)
+
+	maskSealedBids(&auction)
 
-	return bidID, nil
+	return auction, nil
 }
 
-// GetBidsForAuction retrieves all bids for a specific auction
-func GetBidsForAuction(c context.Context, auctionID int) ([]schema.BidResponse, error) {
-	rows, err := config.DB.Query(c, `
-        SELECT b.bid_id, b.buyer_id, u.username, b.bid_amount, b.bid_time, b.auction_id, i.title
-        FROM bids b
-        JOIN users u ON b.buyer_id = u.user_id
-		JOIN items i ON i.auction_id = b.auction_id
-        WHERE b.auction_id = $1
-        ORDER BY b.bid_amount DESC`,
-		auctionID)
+// auctionBidSorts are the orders the bids on an auction can be sorted in
+var auctionBidSorts = map[string]sortOrder{
+	SortPriceDesc: {key: "b.bid_amount", keyType: "numeric", id: "b.bid_id", desc: true},
+	SortNewest:    {key: "b.bid_time", keyType: "timestamp", id: "b.bid_id", desc: true},
+	SortOldest:    {key: "b.bid_time", keyType: "timestamp", id: "b.bid_id"},
+}
 
-	if err != nil {
-		return nil, err
+// sealedBidSorts are the only orders offered while a sealed-bid auction runs.
+// Ordering or paging by amount would reveal the hidden bids and their ranking.
+var sealedBidSorts = map[string]sortOrder{
+	SortNewest: auctionBidSorts[SortNewest],
+	SortOldest: auctionBidSorts[SortOldest],
+}
+
+// GetBidsForAuction retrieves a page of the bids on an auction, highest first
+// unless another sort is asked for. While a sealed-bid auction is running,
+// sealed is true and bids can only be listed by time, newest first by default.
+func GetBidsForAuction(c context.Context, auctionID int, sealed bool, page schema.PageRequest) (schema.Page[schema.BidResponse], error) {
+	sorts, defaultSort := auctionBidSorts, SortPriceDesc
+	if sealed {
+		sorts, defaultSort = sealedBidSorts, SortNewest
+	}
+
+	query := listQuery{
+		columns: `
+        b.bid_id, b.buyer_id, u.username, b.bid_amount, b.bid_time, b.auction_id, i.title, b.is_automated,
+        b.quantity`,
+		from: `
+        bids b
+        JOIN users u ON b.buyer_id = u.user_id
+        JOIN auctions a ON b.auction_id = a.auction_id
+        JOIN items i ON a.item_id = i.item_id
+        WHERE b.auction_id = $1`,
+		args:  []any{auctionID},
+		sorts: sorts,
+		sort:  defaultSort,
 	}
-	defer rows.Close()
 
-	var bids []schema.BidResponse
-	for rows.Next() {
+	return queryPage(c, query, page, func(rows pgx.Rows, extra ...any) (schema.BidResponse, error) {
 		var bid schema.BidResponse
-		err := rows.Scan(
+		err := rows.Scan(append([]any{
 			&bid.BidID, &bid.BuyerID, &bid.BuyerName, &bid.Amount, &bid.BidTime, &bid.AuctionID, &bid.ItemTitle,
-		)
-		if err != nil {
-			return nil, err
-		}
-		bids = append(bids, bid)
-	}
-
-	return bids, rows.Err()
+			&bid.IsAutomated, &bid.Quantity,
+		}, extra...)...)
+		return bid, err
+	})
 }
 
 // GetUserAuctions gets auctions created by a user
 func GetUserAuctions(c context.Context, userID int) ([]schema.AuctionResponse, error) {
 	rows, err := config.DB.Query(c, `
         SELECT a.auction_id, a.item_id, i.title, i.description, 
-               i.starting_bid, COALESCE(i.current_highest_bid, 0), 
+               i.starting_bid, COALESCE(`+auctionHighestBid+`, 0),
                i.seller_id, u.username, 
-               a.start_time, a.end_time, a.auction_status
+               a.start_time, a.end_time, a.auction_status, a.auction_type
         FROM auctions a
         JOIN items i ON a.item_id = i.item_id
         JOIN users u ON i.seller_id = u.user_id
@@ -206,48 +477,65 @@ func GetUserAuctions(c context.Context, userID int) ([]schema.AuctionResponse, e
 		err := rows.Scan(
 			&auction.AuctionID, &auction.ItemID, &auction.Title, &auction.Description,
 			&auction.StartingBid, &auction.CurrentHighestBid, &auction.SellerID, &auction.SellerName,
-			&auction.StartTime, &auction.EndTime, &auction.Status,
+			&auction.StartTime, &auction.EndTime, &auction.Status, &auction.AuctionType,
 		)
 		if err != nil {
 			return nil, err
 		}
+		maskSealedBids(&auction)
 		auctions = append(auctions, auction)
 	}
 
 	return auctions, rows.Err()
 }
 
-// GetUserBids gets bids placed by a user
-func GetUserBids(c context.Context, userID int) ([]schema.BidResponse, error) {
-	rows, err := config.DB.Query(c, `
-        SELECT b.bid_id, b.buyer_id, u.username, b.bid_amount, b.bid_time, a.auction_id, i.title
-        FROM bids b
+// userBidSorts are the orders a user's bids can be sorted in
+var userBidSorts = map[string]sortOrder{
+	SortNewest:     {key: "b.bid_time", keyType: "timestamp", id: "b.bid_id", desc: true},
+	SortOldest:     {key: "b.bid_time", keyType: "timestamp", id: "b.bid_id"},
+	SortEndingSoon: {key: "a.end_time", keyType: "timestamp", id: "b.bid_id"},
+	SortPriceDesc:  {key: "b.bid_amount", keyType: "numeric", id: "b.bid_id", desc: true},
+	SortPriceAsc:   {key: "b.bid_amount", keyType: "numeric", id: "b.bid_id"},
+}
+
+// GetUserBids retrieves a page of the bids placed by a user, newest first
+// unless another sort is asked for. The status filter applies to the auction.
+// Bids on multi-quantity auctions carry how many of their units are currently
+// filled.
+func GetUserBids(c context.Context, userID int, page schema.PageRequest) (schema.Page[schema.BidResponse], error) {
+	query := listQuery{
+		columns: `
+        b.bid_id, b.buyer_id, u.username, b.bid_amount, b.bid_time, a.auction_id, i.title, b.is_automated,
+        b.quantity, i.quantity`,
+		from: `
+        bids b
         JOIN users u ON b.buyer_id = u.user_id
         JOIN auctions a ON b.auction_id = a.auction_id
         JOIN items i ON a.item_id = i.item_id
         WHERE b.buyer_id = $1
-        ORDER BY b.bid_time DESC`,
-		userID)
-
-	if err != nil {
-		return nil, err
+        AND ($2 = '' OR a.auction_status = $2)`,
+		args:  []any{userID, page.Status},
+		sorts: userBidSorts,
+		sort:  SortNewest,
 	}
-	defer rows.Close()
 
-	var bids []schema.BidResponse
-	for rows.Next() {
+	quantities := make(map[int]int)
+	bids, err := queryPage(c, query, page, func(rows pgx.Rows, extra ...any) (schema.BidResponse, error) {
 		var bid schema.BidResponse
-		err := rows.Scan(
+		var quantity int
+		err := rows.Scan(append([]any{
 			&bid.BidID, &bid.BuyerID, &bid.BuyerName, &bid.Amount, &bid.BidTime,
-			&bid.AuctionID, &bid.ItemTitle,
-		)
-		if err != nil {
-			return nil, err
-		}
-		bids = append(bids, bid)
+			&bid.AuctionID, &bid.ItemTitle, &bid.IsAutomated,
+			&bid.Quantity, &quantity,
+		}, extra...)...)
+		quantities[bid.AuctionID] = quantity
+		return bid, err
+	})
+	if err != nil {
+		return bids, err
 	}
 
-	return bids, rows.Err()
+	return bids, setFillStatus(c, bids.Items, quantities)
 }
 
 // DeleteAuction updates an auction's status to 'deleted' and logs it
@@ -272,6 +560,104 @@ func DeleteAuction(c context.Context, auctionID int) error {
 	return err
 }
 
+// ErrAuctionLocked is returned when an auction can no longer be edited because
+// it has bids or has finished
+var ErrAuctionLocked = errors.New("auction can no longer be edited")
+
+// UpdateAuction edits the item and times of an auction that nobody has bid on.
+// A published auction moves between scheduled and open to match its new start
+// time, and a Dutch auction restarts its price from the new starting bid.
+func UpdateAuction(c context.Context, auctionID int, update schema.AuctionUpdateRequest) error {
+	tx, err := config.DB.Begin(c)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(c)
+
+	var itemID int
+	var status string
+	var startTime time.Time
+	err = tx.QueryRow(c, `
+        SELECT item_id, auction_status, start_time
+        FROM auctions
+        WHERE auction_id = $1
+        FOR UPDATE
+    `, auctionID).Scan(&itemID, &status, &startTime)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrAuctionLocked
+	}
+	if err != nil {
+		return err
+	}
+	if status != "draft" && status != "scheduled" && status != "open" {
+		return ErrAuctionLocked
+	}
+
+	// Bids are placed with the auction row locked, so once we hold the lock a
+	// fresh statement sees any bid that committed while we waited for it
+	var hasBids bool
+	err = tx.QueryRow(c, `
+        SELECT EXISTS (SELECT 1 FROM bids WHERE auction_id = $1)
+            OR EXISTS (SELECT 1 FROM automated_bids WHERE auction_id = $1)
+    `, auctionID).Scan(&hasBids)
+	if err != nil {
+		return err
+	}
+	if hasBids {
+		return ErrAuctionLocked
+	}
+
+	if update.StartTime != nil {
+		startTime = *update.StartTime
+		if status != "draft" {
+			status = scheduledStatus(startTime)
+		}
+	}
+
+	_, err = tx.Exec(c, `
+        UPDATE items
+        SET title = COALESCE($2, title),
+            description = COALESCE($3, description),
+            image_path = COALESCE($4, image_path),
+            starting_bid = COALESCE($5, starting_bid)
+        WHERE item_id = $1
+    `, itemID, update.Title, update.Description, update.ImagePath, update.StartingBid)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(c, `
+        UPDATE auctions a
+        SET start_time = $2,
+            end_time = COALESCE($3, a.end_time),
+            auction_status = $4,
+            dutch_current_price = CASE WHEN a.auction_type = 'dutch' THEN i.starting_bid END
+        FROM items i
+        WHERE a.item_id = i.item_id AND a.auction_id = $1
+    `, auctionID, startTime, update.EndTime, status)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(c)
+}
+
+// PublishAuction makes a draft visible. It opens straight away if its start time
+// has passed and is scheduled otherwise. It returns the new status.
+func PublishAuction(c context.Context, auctionID int) (string, error) {
+	var status string
+	err := config.DB.QueryRow(c, `
+        UPDATE auctions
+        SET auction_status = CASE WHEN start_time <= NOW() THEN 'open' ELSE 'scheduled' END
+        WHERE auction_id = $1 AND auction_status = 'draft' AND end_time > NOW()
+        RETURNING auction_status
+    `, auctionID).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrAuctionLocked
+	}
+	return status, err
+}
+
 // UpdateAuctionEndTime updates the end time for an auction
 func UpdateAuctionEndTime(c context.Context, auctionID int, newEndTime time.Time, userID int) (schema.AuctionResponse, error) {
 	tx, err := config.DB.Begin(c)
@@ -297,8 +683,8 @@ func UpdateAuctionEndTime(c context.Context, auctionID int, newEndTime time.Time
 	}
 
 	_, err = tx.Exec(c,
-		"INSERT INTO admin_update_log (old_time, new_time, changed_by) VALUES ($1, $2, 1)",
-		oldEndTime, newEndTime)
+		"INSERT INTO admin_update_log (auction_id, old_time, new_time, changed_by) VALUES ($1, $2, $3, 1)",
+		auctionID, oldEndTime, newEndTime)
 	if err != nil {
 		return schema.AuctionResponse{}, err
 	}
@@ -371,123 +757,112 @@ func GetHighestBidder(c context.Context, auctionID int) (int, float64, error) {
     return winnerID, highestBid, nil
 }
 
-// UpdateAuctionStatus updates the status of an auction
-func UpdateAuctionStatus(c context.Context, auctionID int, status string) error {
-	_, err := config.DB.Exec(c, `
-        UPDATE auctions
-        SET auction_status = $1
-        WHERE auction_id = $2
-    `, status, auctionID)
-
-	return err
-}
-
-// UpdateAutomatedBid sets or updates the maximum automated bid amount for a user on an auction
-func UpdateAutomatedBid(c context.Context, userID int, auctionID int, amount float64) error {
-	tx, err := config.DB.Begin(c)
-	if err != nil {
-		return err
+// GetAuctionWinner returns the winner of an ended auction, their highest bid and
+// the price they pay. English and first-price sealed auctions charge the winning
+// bid; second-price sealed auctions charge the runner-up's bid (or the starting
+// bid if there is none), raised to the reserve if the winning bid met it.
+func GetAuctionWinner(c context.Context, auction schema.AuctionResponse) (int, float64, float64, error) {
+	if auction.AuctionType != AuctionTypeSealedSecondPrice {
+		winnerID, highestBid, err := GetHighestBidder(c, auction.AuctionID)
+		return winnerID, highestBid, highestBid, err
 	}
-	defer tx.Rollback(c)
-
-	var itemID int
-	err = tx.QueryRow(c, `
-        SELECT item_id FROM auctions WHERE auction_id = $1
-    `, auctionID).Scan(&itemID)
 
+	rows, err := config.DB.Query(c, `
+        SELECT buyer_id, bid_amount
+        FROM bids
+        WHERE auction_id = $1
+        ORDER BY bid_amount DESC, bid_time ASC
+        LIMIT 2
+    `, auction.AuctionID)
 	if err != nil {
-		return err
+		return 0, 0, 0, err
 	}
+	defer rows.Close()
 
-	var existingBidID int
-	var existingAmount float64
-	err = tx.QueryRow(c, `
-        SELECT bid_id, bid_amount FROM automated_bids 
-        WHERE auction_id = $1 AND buyer_id = $2
-    `, auctionID, userID).Scan(&existingBidID, &existingAmount)
-
-	if err != nil {
-		if err.Error() == "no rows in result set" {
-			_, err = tx.Exec(c, `
-                INSERT INTO automated_bids (auction_id, buyer_id, bid_amount)
-                VALUES ($1, $2, $3)
-            `, auctionID, userID, amount)
-		} else {
-			return err
+	var bidders []int
+	var amounts []float64
+	for rows.Next() {
+		var buyerID int
+		var amount float64
+		if err := rows.Scan(&buyerID, &amount); err != nil {
+			return 0, 0, 0, err
 		}
-	} else {
-		_, err = tx.Exec(c, `
-            UPDATE automated_bids 
-            SET bid_amount = $3, bid_time = CURRENT_TIMESTAMP
-            WHERE auction_id = $1 AND buyer_id = $2
-        `, auctionID, userID, amount)
+		bidders = append(bidders, buyerID)
+		amounts = append(amounts, amount)
 	}
-
-	if err != nil {
-		return err
+	if err := rows.Err(); err != nil {
+		return 0, 0, 0, err
 	}
 
-	var currentHighestBidderID sql.NullInt32
-	err = tx.QueryRow(c, `
-        SELECT current_highest_bidder FROM items WHERE item_id = $1
-    `, itemID).Scan(&currentHighestBidderID)
-
-	if err != nil && err.Error() != "no rows in result set" {
-		return err
+	if len(bidders) == 0 {
+		return 0, 0, 0, nil
 	}
 
-	if !currentHighestBidderID.Valid || int(currentHighestBidderID.Int32) == userID {
-		_, err = tx.Exec(c, `
-            UPDATE items 
-            SET highest_automated_bid = $1
-            WHERE item_id = $2
-        `, amount, itemID)
-
-		if err != nil {
-			return err
-		}
+	price := auction.StartingBid
+	if len(amounts) > 1 {
+		price = amounts[1]
+	}
+	if auction.ReservePrice > 0 && amounts[0] >= auction.ReservePrice {
+		price = max(price, auction.ReservePrice)
 	}
 
-	return tx.Commit(c)
+	return bidders[0], amounts[0], price, nil
 }
 
-// GetHighestAutomatedBid returns the highest automated bid amount for an auction
-func GetHighestAutomatedBid(c context.Context, auctionID int) (float64, error) {
-	var highestAutomatedBid float64
+// CloseAuction marks an ended auction as closed, records why it closed and
+// creates a transaction for each sale, all in one database transaction so a
+// sold auction is never left without its transactions. The end time is checked
+// again so an auction extended by a late bid stays open. It returns the
+// transaction IDs in the order of sales.
+func CloseAuction(c context.Context, auctionID int, reason string, sales []Sale) ([]int, error) {
+	tx, err := config.DB.Begin(c)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(c)
 
-	err := config.DB.QueryRow(c, `
-        SELECT COALESCE(i.highest_automated_bid, 0)
-        FROM items i
-        JOIN auctions a ON i.item_id = a.item_id
-        WHERE a.auction_id = $1
-    `, auctionID).Scan(&highestAutomatedBid)
+	result, err := tx.Exec(c, `
+        UPDATE auctions
+        SET auction_status = 'closed', close_reason = $2
+        WHERE auction_id = $1 AND close_reason IS NULL AND auction_status != 'deleted'
+        AND end_time <= NOW()
+    `, auctionID, reason)
 
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	return highestAutomatedBid, nil
-}
+	if result.RowsAffected() == 0 {
+		return nil, fmt.Errorf("auction already closed or still running")
+	}
 
-// GetCurrentHighestBidder returns the user ID of the current highest bidder for an auction
-func GetCurrentHighestBidder(c context.Context, auctionID int) (int, error) {
-	var bidderID int
+	transactionIDs := make([]int, len(sales))
+	for i, sale := range sales {
+		err = tx.QueryRow(c, `
+            INSERT INTO transactions (auction_id, buyer_id, quantity, amount)
+            VALUES ($1, $2, $3, $4)
+            RETURNING transaction_id
+        `, auctionID, sale.BuyerID, sale.Quantity, sale.Amount).Scan(&transactionIDs[i])
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	err := config.DB.QueryRow(c, `
-        SELECT i.current_highest_bidder
-        FROM items i
-        JOIN auctions a ON i.item_id = a.item_id
-        WHERE a.auction_id = $1
-    `, auctionID).Scan(&bidderID)
+	return transactionIDs, tx.Commit(c)
+}
 
-	if err != nil {
-		return 0, err
-	}
+// UpdateAuctionStatus updates the status of an auction
+func UpdateAuctionStatus(c context.Context, auctionID int, status string) error {
+	_, err := config.DB.Exec(c, `
+        UPDATE auctions
+        SET auction_status = $1
+        WHERE auction_id = $2
+    `, status, auctionID)
 
-	return bidderID, nil
+	return err
 }
 
-// GetAuctionsToOpen returns auctions where start_time is in the past but status is not "open"
+// GetAuctionsToOpen returns scheduled auctions whose start_time has passed
 func GetAuctionsToOpen(c context.Context) ([]schema.AuctionResponse, error) {
 	rows, err := config.DB.Query(c, `
         SELECT a.auction_id, a.item_id, i.title, i.description, i.starting_bid,
@@ -499,8 +874,7 @@ func GetAuctionsToOpen(c context.Context) ([]schema.AuctionResponse, error) {
         JOIN users u ON i.seller_id = u.user_id
         WHERE a.start_time <= NOW() 
         AND a.end_time > NOW()
-        AND a.auction_status != 'open'
-        AND a.auction_status != 'deleted'
+        AND a.auction_status = 'scheduled'
     `)
 
 	if err != nil {
@@ -531,13 +905,13 @@ func GetAuctionsToClose(c context.Context) ([]schema.AuctionResponse, error) {
         SELECT a.auction_id, a.item_id, i.title, i.description, i.starting_bid,
         COALESCE(i.current_highest_bid, i.starting_bid) as highest_bid,
         i.seller_id, u.username as seller_name,
-        a.start_time, a.end_time, a.auction_status, i.image_path
+        a.start_time, a.end_time, a.auction_status, i.image_path,
+        COALESCE(i.reserve_price, 0), a.auction_type, i.quantity, a.auto_relist_remaining
         FROM auctions a
         JOIN items i ON a.item_id = i.item_id
         JOIN users u ON i.seller_id = u.user_id
         WHERE a.end_time <= NOW()
-        AND a.auction_status != 'closed'
-        AND a.auction_status != 'deleted'
+        AND a.auction_status IN ('scheduled', 'open')
     `)
 
 	if err != nil {
@@ -552,6 +926,7 @@ func GetAuctionsToClose(c context.Context) ([]schema.AuctionResponse, error) {
 			&auction.AuctionID, &auction.ItemID, &auction.Title, &auction.Description,
 			&auction.StartingBid, &auction.CurrentHighestBid, &auction.SellerID, &auction.SellerName,
 			&auction.StartTime, &auction.EndTime, &auction.Status, &auction.ImagePath,
+			&auction.ReservePrice, &auction.AuctionType, &auction.Quantity, &auction.AutoRelist,
 		)
 		if err != nil {
 			return nil, err
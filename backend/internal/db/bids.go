@@ -0,0 +1,639 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"Online-Auction-System/backend/config"
+	"Online-Auction-System/backend/internal/schema"
+)
+
+// BidOutcome describes how the bid engine resolved a bid
+type BidOutcome string
+
+const (
+	BidAccepted      BidOutcome = "accepted"
+	BidOutbidByProxy BidOutcome = "outbid_by_proxy"
+	BidRejected      BidOutcome = "rejected"
+)
+
+// ErrAuctionNotFound is returned when a bid targets an auction that does not exist
+var ErrAuctionNotFound = errors.New("auction not found")
+
+// BidResult is the typed result of a PlaceBid call
+type BidResult struct {
+	Outcome        BidOutcome      `json:"outcome"`
+	Reason         string          `json:"reason,omitempty"`
+	BidID          int             `json:"bid_id,omitempty"`
+	Amount         float64         `json:"amount"`
+	HighestBid     float64         `json:"highest_bid"`
+	HighestBidder  int             `json:"highest_bidder"`
+	NextMinBid     float64         `json:"next_min_bid"`
+	Extended       bool            `json:"extended"`
+	EndTime        time.Time       `json:"end_time,omitempty"`
+	Sealed         bool            `json:"sealed"`
+	Revised        bool            `json:"revised,omitempty"`
+	Quantity       int             `json:"quantity,omitempty"`
+	Filled         int             `json:"filled_quantity,omitempty"`
+	PreviousBidder int             `json:"-"`
+	PreviousBid    float64         `json:"-"`
+	Displaced      map[int]float64 `json:"-"`
+}
+
+// lockedAuction is the auction and item state read under a row lock
+type lockedAuction struct {
+	itemID        int
+	status        string
+	auctionType   string
+	started       bool
+	ended         bool
+	sellerID      int
+	startingBid   float64
+	quantity      int
+	highestBid    sql.NullFloat64
+	highestBidder sql.NullInt64
+	buyNowPrice   sql.NullFloat64
+	reservePrice  sql.NullFloat64
+	dutchPrice    sql.NullFloat64 // the Dutch schedule's price now, not the stored one
+	increments    []schema.BidIncrementTier
+}
+
+// currentBid returns the standing price, or the starting bid if nobody has bid yet
+func (a lockedAuction) currentBid() float64 {
+	if a.highestBid.Valid {
+		return a.highestBid.Float64
+	}
+	return a.startingBid
+}
+
+// nextMinBid returns the lowest amount the next bid may have. The first bid may
+// match the starting bid; later ones must raise the price by the increment.
+// Sealed bids only have to meet the starting bid.
+func (a lockedAuction) nextMinBid() float64 {
+	if !a.highestBid.Valid || IsSealed(a.auctionType) {
+		return a.startingBid
+	}
+	return NextMinBid(a.increments, a.highestBid.Float64)
+}
+
+// minBidReason returns why amount is too low to bid against the standing price,
+// or "" if it is enough. With recordBid it is PlaceBid's check-then-write: both
+// run while the auction row is locked, so two bids can never pass the check
+// against the same standing price.
+func (a lockedAuction) minBidReason(amount float64, automated bool) string {
+	if amount >= a.nextMinBid() {
+		return ""
+	}
+	if automated {
+		return fmt.Sprintf("Automated bid amount must be at least %.2f", a.nextMinBid())
+	}
+	return fmt.Sprintf("Bid amount must be at least %.2f", a.nextMinBid())
+}
+
+// recordBid makes buyerID the highest bidder at amount
+func (a *lockedAuction) recordBid(buyerID int, amount float64) {
+	a.highestBid = sql.NullFloat64{Float64: amount, Valid: true}
+	a.highestBidder = sql.NullInt64{Int64: int64(buyerID), Valid: true}
+}
+
+// raise returns the price needed to beat amount, capped at ceiling
+func (a lockedAuction) raise(amount, ceiling float64) float64 {
+	return min(NextMinBid(a.increments, amount), ceiling)
+}
+
+// leader returns the current highest bidder, or 0 if nobody has bid yet
+func (a lockedAuction) leader() int {
+	if a.highestBidder.Valid {
+		return int(a.highestBidder.Int64)
+	}
+	return 0
+}
+
+// contender is one bidder's best standing offer while proxies are resolved
+type contender struct {
+	buyerID   int
+	maxAmount float64
+	placedAt  time.Time
+	automated bool
+}
+
+// PlaceBid validates and records a bid in a single transaction. The auction and
+// item rows stay locked until commit, so concurrent bids are serialised. When
+// automated is true, amount is the bidder's maximum and the engine bids on their
+// behalf. Every bid, manual or automated, is followed by proxy resolution.
+// quantity only applies to multi-quantity auctions, where amount is per unit.
+func PlaceBid(c context.Context, auctionID, buyerID int, amount float64, quantity int, automated bool) (BidResult, error) {
+	tx, err := config.DB.Begin(c)
+	if err != nil {
+		return BidResult{}, err
+	}
+	defer tx.Rollback(c)
+
+	auction, err := lockAuction(c, tx, auctionID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return BidResult{}, ErrAuctionNotFound
+	}
+	if err != nil {
+		return BidResult{}, err
+	}
+
+	result := BidResult{
+		Amount:         amount,
+		HighestBid:     auction.currentBid(),
+		HighestBidder:  auction.leader(),
+		PreviousBidder: auction.leader(),
+		PreviousBid:    auction.currentBid(),
+		NextMinBid:     auction.nextMinBid(),
+	}
+
+	if reason := validateBid(auction, buyerID); reason != "" {
+		return rejectBid(result, reason), nil
+	}
+
+	if auction.auctionType == AuctionTypeDutch {
+		return rejectBid(result, "Dutch auctions do not take bids; accept the current price instead"), nil
+	}
+
+	if auction.quantity > 1 {
+		if automated {
+			return rejectBid(result, "Automated bids are not available in multi-quantity auctions"), nil
+		}
+		return placeMultiUnitBid(c, tx, auctionID, buyerID, amount, quantity, auction)
+	}
+	if quantity > 1 {
+		return rejectBid(result, "This auction offers a single unit"), nil
+	}
+
+	if IsSealed(auction.auctionType) {
+		if automated {
+			return rejectBid(result, "Automated bids are not available in sealed-bid auctions"), nil
+		}
+		return placeSealedBid(c, tx, auctionID, buyerID, amount, auction)
+	}
+
+	if reason := auction.minBidReason(amount, automated); reason != "" {
+		return rejectBid(result, reason), nil
+	}
+
+	if automated {
+		err = upsertAutomatedBid(c, tx, auctionID, buyerID, amount)
+	} else {
+		result.BidID, err = insertBid(c, tx, auctionID, buyerID, amount, false)
+		auction.recordBid(buyerID, amount)
+	}
+	if err != nil {
+		return BidResult{}, err
+	}
+
+	proxyBids, err := resolveProxies(c, tx, auctionID, &auction)
+	if err != nil {
+		return BidResult{}, err
+	}
+
+	if automated {
+		result.BidID = proxyBids[buyerID]
+		if result.BidID != 0 {
+			result.Amount, err = getBidAmount(c, tx, result.BidID)
+			if err != nil {
+				return BidResult{}, err
+			}
+		}
+	}
+
+	result.HighestBid = auction.currentBid()
+	result.HighestBidder = auction.leader()
+	result.NextMinBid = auction.nextMinBid()
+	result.Outcome = BidAccepted
+	if result.HighestBidder != buyerID {
+		result.Outcome = BidOutbidByProxy
+	}
+
+	if result.BidID != 0 || len(proxyBids) > 0 {
+		result.Extended, result.EndTime, err = applySoftClose(c, tx, auctionID, buyerID)
+		if err != nil {
+			return BidResult{}, err
+		}
+	}
+
+	if err = tx.Commit(c); err != nil {
+		return BidResult{}, err
+	}
+
+	return result, nil
+}
+
+// BuyNowAvailable reports whether the buy-now option is still offered. It is
+// withdrawn once the highest bid reaches the configured share of the price.
+func BuyNowAvailable(buyNowPrice, highestBid float64) bool {
+	return buyNowPrice > 0 && highestBid < buyNowPrice*config.BuyNowCutoff()
+}
+
+// BuyNow sells the item to buyerID at the buy-now price. The purchase is
+// recorded as the winning bid, the auction is closed as sold and the transaction
+// is created in the same database transaction. It returns the transaction ID.
+func BuyNow(c context.Context, auctionID, buyerID int) (BidResult, int, error) {
+	tx, err := config.DB.Begin(c)
+	if err != nil {
+		return BidResult{}, 0, err
+	}
+	defer tx.Rollback(c)
+
+	auction, err := lockAuction(c, tx, auctionID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return BidResult{}, 0, ErrAuctionNotFound
+	}
+	if err != nil {
+		return BidResult{}, 0, err
+	}
+
+	result := BidResult{
+		HighestBid:     auction.currentBid(),
+		HighestBidder:  auction.leader(),
+		PreviousBidder: auction.leader(),
+		PreviousBid:    auction.currentBid(),
+	}
+
+	if reason := validateBid(auction, buyerID); reason != "" {
+		return rejectBid(result, reason), 0, nil
+	}
+
+	var highestBid float64
+	if auction.highestBid.Valid {
+		highestBid = auction.highestBid.Float64
+	}
+	if auction.auctionType != AuctionTypeEnglish || auction.quantity > 1 || !BuyNowAvailable(auction.buyNowPrice.Float64, highestBid) {
+		return rejectBid(result, "Buy now is not available for this auction"), 0, nil
+	}
+
+	return sellNow(c, tx, auctionID, buyerID, auction.buyNowPrice.Float64, auction, result)
+}
+
+// sellNow records price as the winning bid, closes the auction as sold and
+// creates the transaction, then commits tx. It returns the transaction ID.
+func sellNow(c context.Context, tx pgx.Tx, auctionID, buyerID int, price float64, auction lockedAuction, result BidResult) (BidResult, int, error) {
+	var err error
+	result.BidID, err = insertBid(c, tx, auctionID, buyerID, price, false)
+	if err != nil {
+		return BidResult{}, 0, err
+	}
+
+	if err := setHighestBid(c, tx, auctionID, auction.itemID, buyerID, price); err != nil {
+		return BidResult{}, 0, err
+	}
+
+	_, err = tx.Exec(c, `
+        UPDATE auctions
+        SET auction_status = 'closed', close_reason = $2, end_time = NOW()
+        WHERE auction_id = $1
+    `, auctionID, CloseReasonSold)
+	if err != nil {
+		return BidResult{}, 0, err
+	}
+
+	var transactionID int
+	err = tx.QueryRow(c, `
+        INSERT INTO transactions (auction_id, buyer_id, amount)
+        VALUES ($1, $2, $3)
+        RETURNING transaction_id
+    `, auctionID, buyerID, price).Scan(&transactionID)
+	if err != nil {
+		return BidResult{}, 0, err
+	}
+
+	if err = tx.Commit(c); err != nil {
+		return BidResult{}, 0, err
+	}
+
+	result.Outcome = BidAccepted
+	result.Amount = price
+	result.HighestBid = price
+	result.HighestBidder = buyerID
+	return result, transactionID, nil
+}
+
+// placeSealedBid records or revises the bidder's single sealed bid. The item
+// keeps tracking the leading bid so the auction can be settled at close, but the
+// result carries nothing that would reveal other bids.
+func placeSealedBid(c context.Context, tx pgx.Tx, auctionID, buyerID int, amount float64, auction lockedAuction) (BidResult, error) {
+	result := BidResult{Amount: amount, NextMinBid: auction.startingBid, Sealed: true}
+
+	if amount < auction.startingBid {
+		return rejectBid(result, fmt.Sprintf("Bid amount must be at least %.2f", auction.startingBid)), nil
+	}
+
+	err := tx.QueryRow(c, `
+        UPDATE bids
+        SET bid_amount = $3, bid_time = CURRENT_TIMESTAMP
+        WHERE auction_id = $1 AND buyer_id = $2
+        RETURNING bid_id
+    `, auctionID, buyerID, amount).Scan(&result.BidID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		result.BidID, err = insertBid(c, tx, auctionID, buyerID, amount, false)
+	} else {
+		result.Revised = true
+	}
+	if err != nil {
+		return BidResult{}, err
+	}
+
+	// A revision can lower a bid, so the leader is recomputed rather than left to the trigger
+	var leaderID int
+	var leadingBid float64
+	err = tx.QueryRow(c, `
+        SELECT buyer_id, bid_amount
+        FROM bids
+        WHERE auction_id = $1
+        ORDER BY bid_amount DESC, bid_time ASC
+        LIMIT 1
+    `, auctionID).Scan(&leaderID, &leadingBid)
+	if err != nil {
+		return BidResult{}, err
+	}
+
+	if err := setHighestBid(c, tx, auctionID, auction.itemID, leaderID, leadingBid); err != nil {
+		return BidResult{}, err
+	}
+
+	if err := tx.Commit(c); err != nil {
+		return BidResult{}, err
+	}
+
+	result.Outcome = BidAccepted
+	return result, nil
+}
+
+// lockAuction reads the auction and its item with FOR UPDATE
+func lockAuction(c context.Context, tx pgx.Tx, auctionID int) (lockedAuction, error) {
+	var auction lockedAuction
+	err := tx.QueryRow(c, `
+        SELECT a.item_id, a.auction_status, a.auction_type, a.start_time <= NOW(), a.end_time <= NOW(),
+               i.seller_id, i.starting_bid, i.quantity, i.current_highest_bid, i.current_highest_bidder,
+               i.buy_now_price, i.reserve_price,
+               CASE WHEN a.auction_type = 'dutch' THEN `+dutchScheduledPrice+` END
+        FROM auctions a
+        JOIN items i ON a.item_id = i.item_id
+        WHERE a.auction_id = $1
+        FOR UPDATE
+    `, auctionID).Scan(
+		&auction.itemID, &auction.status, &auction.auctionType, &auction.started, &auction.ended,
+		&auction.sellerID, &auction.startingBid, &auction.quantity, &auction.highestBid, &auction.highestBidder,
+		&auction.buyNowPrice, &auction.reservePrice, &auction.dutchPrice,
+	)
+	if err != nil {
+		return auction, err
+	}
+
+	auction.increments, err = queryBidIncrements(c, tx, auctionID)
+	return auction, err
+}
+
+// validateBid returns the reason a bid cannot be placed, or "" if it can
+func validateBid(auction lockedAuction, buyerID int) string {
+	switch {
+	case auction.status != "open":
+		return "Cannot bid on a closed or deleted auction"
+	case !auction.started:
+		return "Auction has not started yet"
+	case auction.ended:
+		return "Auction has already ended"
+	case auction.sellerID == buyerID:
+		return "Sellers cannot bid on their own auction"
+	}
+	return ""
+}
+
+func rejectBid(result BidResult, reason string) BidResult {
+	result.Outcome = BidRejected
+	result.Reason = reason
+	return result
+}
+
+// resolveProxies settles the standing bid against every automated bid on the
+// auction, as planned by planProxies. Synthetic bids are recorded for both
+// sides and the item is updated in place. It returns the synthetic bid IDs
+// keyed by bidder.
+func resolveProxies(c context.Context, tx pgx.Tx, auctionID int, auction *lockedAuction) (map[int]int, error) {
+	contenders, err := loadContenders(c, tx, auctionID, *auction)
+	if err != nil || len(contenders) == 0 {
+		return nil, err
+	}
+
+	plan := planProxies(*auction, contenders)
+	bidIDs := make(map[int]int)
+
+	// The runner-up's proxy is pushed to its ceiling before the winner answers
+	if plan.runnerUp != nil {
+		bidID, err := insertBid(c, tx, auctionID, plan.runnerUp.buyerID, plan.runnerUp.maxAmount, true)
+		if err != nil {
+			return nil, err
+		}
+		bidIDs[plan.runnerUp.buyerID] = bidID
+	}
+
+	if !plan.winnerBids {
+		return bidIDs, nil
+	}
+
+	bidID, err := insertBid(c, tx, auctionID, plan.winner.buyerID, plan.price, true)
+	if err != nil {
+		return nil, err
+	}
+	bidIDs[plan.winner.buyerID] = bidID
+
+	if err := setHighestBid(c, tx, auctionID, auction.itemID, plan.winner.buyerID, plan.price); err != nil {
+		return nil, err
+	}
+
+	auction.recordBid(plan.winner.buyerID, plan.price)
+
+	return bidIDs, nil
+}
+
+// proxyPlan is how proxy resolution moves an auction: who leads and at what
+// price, and which runner-up proxy, if any, bids its ceiling first
+type proxyPlan struct {
+	winner     contender
+	price      float64
+	runnerUp   *contender
+	winnerBids bool
+}
+
+// planProxies works out proxy resolution for contenders ranked by
+// rankContenders. The highest ceiling wins, ties go to whoever set it first,
+// and the price is the runner-up's ceiling plus the increment at that price,
+// capped at the winner's ceiling. A lone proxy with no bids yet opens at the
+// starting bid. A winner whose ceiling reaches the reserve price is bid up to
+// the reserve, so the auction does not close unsold below a price they agreed to.
+func planProxies(auction lockedAuction, contenders []contender) proxyPlan {
+	leader, price := auction.leader(), auction.currentBid()
+	plan := proxyPlan{winner: contenders[0], price: price}
+
+	if len(contenders) > 1 {
+		runnerUp := contenders[1]
+		plan.price = max(auction.raise(runnerUp.maxAmount, plan.winner.maxAmount), price)
+		if runnerUp.automated && runnerUp.maxAmount > price {
+			plan.runnerUp = &runnerUp
+		}
+	} else if leader == 0 {
+		plan.price = auction.startingBid
+	} else if plan.winner.buyerID != leader {
+		plan.price = auction.raise(price, plan.winner.maxAmount)
+	}
+
+	if reserve := auction.reservePrice; reserve.Valid && plan.price < reserve.Float64 && plan.winner.maxAmount >= reserve.Float64 {
+		plan.price = reserve.Float64
+	}
+
+	plan.winnerBids = plan.winner.buyerID != leader || plan.price != price || plan.runnerUp != nil
+	return plan
+}
+
+// loadContenders returns each bidder's best offer that can still compete with the
+// standing bid, ordered by ceiling and then by the time it was placed
+func loadContenders(c context.Context, tx pgx.Tx, auctionID int, auction lockedAuction) ([]contender, error) {
+	rows, err := tx.Query(c, `
+        SELECT buyer_id, bid_amount, bid_time
+        FROM automated_bids
+        WHERE auction_id = $1 AND bid_amount >= $2
+    `, auctionID, auction.currentBid())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byBuyer := make(map[int]contender)
+	for rows.Next() {
+		proxy := contender{automated: true}
+		if err := rows.Scan(&proxy.buyerID, &proxy.maxAmount, &proxy.placedAt); err != nil {
+			return nil, err
+		}
+		byBuyer[proxy.buyerID] = proxy
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(byBuyer) == 0 {
+		return nil, nil
+	}
+
+	// The leader competes with whichever is higher: their ceiling or their standing bid
+	if leader := auction.leader(); leader != 0 {
+		if proxy, ok := byBuyer[leader]; !ok || proxy.maxAmount < auction.currentBid() {
+			standing := contender{buyerID: leader, maxAmount: auction.currentBid()}
+			err := tx.QueryRow(c, `
+                SELECT COALESCE(MIN(bid_time), NOW()) FROM bids
+                WHERE auction_id = $1 AND buyer_id = $2 AND bid_amount = $3
+            `, auctionID, leader, standing.maxAmount).Scan(&standing.placedAt)
+			if err != nil {
+				return nil, err
+			}
+			byBuyer[leader] = standing
+		}
+	}
+
+	contenders := make([]contender, 0, len(byBuyer))
+	for _, entry := range byBuyer {
+		contenders = append(contenders, entry)
+	}
+	rankContenders(contenders)
+
+	return contenders, nil
+}
+
+// rankContenders orders contenders by ceiling, highest first, and then by the
+// time their offer was placed, earliest first
+func rankContenders(contenders []contender) {
+	sort.Slice(contenders, func(i, j int) bool {
+		if contenders[i].maxAmount != contenders[j].maxAmount {
+			return contenders[i].maxAmount > contenders[j].maxAmount
+		}
+		return contenders[i].placedAt.Before(contenders[j].placedAt)
+	})
+}
+
+// applySoftClose pushes end_time forward when a bid lands inside the auction's
+// soft-close window, unless the extension cap has been reached. The change is
+// written to the end-time audit log.
+func applySoftClose(c context.Context, tx pgx.Tx, auctionID, buyerID int) (bool, time.Time, error) {
+	var oldEndTime, newEndTime time.Time
+	err := tx.QueryRow(c, `
+        UPDATE auctions a
+        SET end_time = a.end_time + make_interval(mins => a.soft_close_extension),
+            extension_count = a.extension_count + 1
+        FROM (SELECT end_time FROM auctions WHERE auction_id = $1) old
+        WHERE a.auction_id = $1
+          AND a.soft_close_window IS NOT NULL
+          AND a.soft_close_extension IS NOT NULL
+          AND a.end_time - NOW() <= make_interval(mins => a.soft_close_window)
+          AND (a.soft_close_max_extensions IS NULL OR a.extension_count < a.soft_close_max_extensions)
+        RETURNING old.end_time, a.end_time
+    `, auctionID).Scan(&oldEndTime, &newEndTime)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, time.Time{}, nil
+	}
+	if err != nil {
+		return false, time.Time{}, err
+	}
+
+	_, err = tx.Exec(c,
+		"INSERT INTO admin_update_log (auction_id, old_time, new_time, changed_by) VALUES ($1, $2, $3, $4)",
+		auctionID, oldEndTime, newEndTime, buyerID)
+	if err != nil {
+		return false, time.Time{}, err
+	}
+
+	return true, newEndTime, nil
+}
+
+func insertBid(c context.Context, tx pgx.Tx, auctionID, buyerID int, amount float64, automated bool) (int, error) {
+	var bidID int
+	err := tx.QueryRow(c, `
+        INSERT INTO bids (auction_id, buyer_id, bid_amount, is_automated)
+        VALUES ($1, $2, $3, $4)
+        RETURNING bid_id`,
+		auctionID, buyerID, amount, automated).Scan(&bidID)
+	return bidID, err
+}
+
+func getBidAmount(c context.Context, tx pgx.Tx, bidID int) (float64, error) {
+	var amount float64
+	err := tx.QueryRow(c, "SELECT bid_amount FROM bids WHERE bid_id = $1", bidID).Scan(&amount)
+	return amount, err
+}
+
+func upsertAutomatedBid(c context.Context, tx pgx.Tx, auctionID, buyerID int, maxAmount float64) error {
+	_, err := tx.Exec(c, `
+        INSERT INTO automated_bids (auction_id, buyer_id, bid_amount)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (auction_id, buyer_id)
+        DO UPDATE SET bid_amount = EXCLUDED.bid_amount, bid_time = CURRENT_TIMESTAMP
+    `, auctionID, buyerID, maxAmount)
+	return err
+}
+
+// setHighestBid records the resolved leader on the item. The bid trigger only
+// moves the lead on a strictly higher amount, so ties won on time land here.
+func setHighestBid(c context.Context, tx pgx.Tx, auctionID, itemID, buyerID int, amount float64) error {
+	_, err := tx.Exec(c, `
+        UPDATE items
+        SET current_highest_bid = $1, current_highest_bidder = $2
+        WHERE item_id = $3
+    `, amount, buyerID, itemID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(c, `
+        INSERT INTO auction_participants (auction_id, user_id, user_role)
+        VALUES ($1, $2, 'buyer')
+        ON CONFLICT DO NOTHING
+    `, auctionID, buyerID)
+	return err
+}
@@ -0,0 +1,401 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"Online-Auction-System/backend/config"
+)
+
+func TestRankContenders(t *testing.T) {
+	early := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	late := early.Add(time.Minute)
+
+	contenders := []contender{
+		{buyerID: 1, maxAmount: 50, placedAt: early},
+		{buyerID: 2, maxAmount: 80, placedAt: late},
+		{buyerID: 3, maxAmount: 80, placedAt: early},
+		{buyerID: 4, maxAmount: 60, placedAt: early},
+	}
+	rankContenders(contenders)
+
+	want := []int{3, 2, 4, 1}
+	for i, buyerID := range want {
+		if contenders[i].buyerID != buyerID {
+			t.Fatalf("rank %d: got bidder %d, want %d", i, contenders[i].buyerID, buyerID)
+		}
+	}
+}
+
+func TestPlanProxies(t *testing.T) {
+	early := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	late := early.Add(time.Minute)
+
+	standing := func(buyerID int, amount float64) lockedAuction {
+		return lockedAuction{
+			startingBid:   10,
+			increments:    DefaultBidIncrements,
+			highestBid:    sql.NullFloat64{Float64: amount, Valid: true},
+			highestBidder: sql.NullInt64{Int64: int64(buyerID), Valid: true},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		auction     lockedAuction
+		contenders  []contender
+		winner      int
+		price       float64
+		runnerUp    int
+		runnerUpBid float64
+		winnerBids  bool
+	}{
+		{
+			name:    "lone proxy opens at the starting bid",
+			auction: lockedAuction{startingBid: 10, increments: DefaultBidIncrements},
+			contenders: []contender{
+				{buyerID: 1, maxAmount: 50, placedAt: early, automated: true},
+			},
+			winner:     1,
+			price:      10,
+			winnerBids: true,
+		},
+		{
+			name:    "runner-up proxy is pushed to its ceiling",
+			auction: standing(2, 20),
+			contenders: []contender{
+				{buyerID: 1, maxAmount: 50, placedAt: early, automated: true},
+				{buyerID: 2, maxAmount: 30, placedAt: late, automated: true},
+			},
+			winner:      1,
+			price:       31,
+			runnerUp:    2,
+			runnerUpBid: 30,
+			winnerBids:  true,
+		},
+		{
+			name:    "winner is capped at its own ceiling",
+			auction: standing(2, 20),
+			contenders: []contender{
+				{buyerID: 1, maxAmount: 30.5, placedAt: early, automated: true},
+				{buyerID: 2, maxAmount: 30, placedAt: late, automated: true},
+			},
+			winner:      1,
+			price:       30.5,
+			runnerUp:    2,
+			runnerUpBid: 30,
+			winnerBids:  true,
+		},
+		{
+			name:    "earliest bid wins a tie",
+			auction: standing(2, 20),
+			contenders: []contender{
+				{buyerID: 1, maxAmount: 40, placedAt: early, automated: true},
+				{buyerID: 2, maxAmount: 40, placedAt: late, automated: true},
+			},
+			winner:      1,
+			price:       40,
+			runnerUp:    2,
+			runnerUpBid: 40,
+			winnerBids:  true,
+		},
+		{
+			name:    "manual leader is outbid by one increment",
+			auction: standing(2, 20),
+			contenders: []contender{
+				{buyerID: 1, maxAmount: 50, placedAt: early, automated: true},
+				{buyerID: 2, maxAmount: 20, placedAt: late},
+			},
+			winner:     1,
+			price:      21,
+			winnerBids: true,
+		},
+		{
+			name: "winner whose ceiling reaches the reserve is bid up to it",
+			auction: func() lockedAuction {
+				auction := standing(2, 20)
+				auction.reservePrice = sql.NullFloat64{Float64: 45, Valid: true}
+				return auction
+			}(),
+			contenders: []contender{
+				{buyerID: 1, maxAmount: 50, placedAt: early, automated: true},
+				{buyerID: 2, maxAmount: 30, placedAt: late, automated: true},
+			},
+			winner:      1,
+			price:       45,
+			runnerUp:    2,
+			runnerUpBid: 30,
+			winnerBids:  true,
+		},
+		{
+			name: "winner below the reserve is not bid past the runner-up",
+			auction: func() lockedAuction {
+				auction := standing(2, 20)
+				auction.reservePrice = sql.NullFloat64{Float64: 60, Valid: true}
+				return auction
+			}(),
+			contenders: []contender{
+				{buyerID: 1, maxAmount: 50, placedAt: early, automated: true},
+				{buyerID: 2, maxAmount: 30, placedAt: late, automated: true},
+			},
+			winner:      1,
+			price:       31,
+			runnerUp:    2,
+			runnerUpBid: 30,
+			winnerBids:  true,
+		},
+		{
+			name:    "leading proxy with no challenger stays put",
+			auction: standing(1, 20),
+			contenders: []contender{
+				{buyerID: 1, maxAmount: 50, placedAt: early, automated: true},
+			},
+			winner: 1,
+			price:  20,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			plan := planProxies(test.auction, test.contenders)
+
+			if plan.winner.buyerID != test.winner || plan.price != test.price {
+				t.Errorf("got bidder %d leading at %.2f, want %d at %.2f",
+					plan.winner.buyerID, plan.price, test.winner, test.price)
+			}
+			if plan.winnerBids != test.winnerBids {
+				t.Errorf("got winnerBids %v, want %v", plan.winnerBids, test.winnerBids)
+			}
+
+			switch {
+			case test.runnerUp == 0 && plan.runnerUp != nil:
+				t.Errorf("runner-up %d bid, want no runner-up bid", plan.runnerUp.buyerID)
+			case test.runnerUp != 0 && plan.runnerUp == nil:
+				t.Errorf("no runner-up bid, want bidder %d at %.2f", test.runnerUp, test.runnerUpBid)
+			case test.runnerUp != 0 && (plan.runnerUp.buyerID != test.runnerUp || plan.runnerUp.maxAmount != test.runnerUpBid):
+				t.Errorf("runner-up %d bid %.2f, want %d at %.2f",
+					plan.runnerUp.buyerID, plan.runnerUp.maxAmount, test.runnerUp, test.runnerUpBid)
+			}
+		})
+	}
+}
+
+// TestConcurrentBidsCannotBothWin runs PlaceBid's check-then-write from
+// hundreds of goroutines, with a mutex standing in for the auction row lock.
+// Many bidders offer the same amounts, and only one bid can win at each price.
+func TestConcurrentBidsCannotBothWin(t *testing.T) {
+	const bidders = 300
+
+	auction := lockedAuction{startingBid: 10, increments: DefaultBidIncrements}
+	type accepted struct {
+		buyerID int
+		amount  float64
+	}
+
+	var (
+		rowLock sync.Mutex
+		wins    []accepted
+		wg      sync.WaitGroup
+	)
+	start := make(chan struct{})
+	for i := 0; i < bidders; i++ {
+		wg.Add(1)
+		go func(buyerID int, amount float64) {
+			defer wg.Done()
+			<-start
+
+			rowLock.Lock()
+			defer rowLock.Unlock()
+			if auction.minBidReason(amount, false) == "" {
+				auction.recordBid(buyerID, amount)
+				wins = append(wins, accepted{buyerID, amount})
+			}
+		}(i+1, float64(10+i%30))
+	}
+	close(start)
+	wg.Wait()
+
+	if len(wins) == 0 {
+		t.Fatal("no bid was accepted")
+	}
+
+	won := make(map[float64]int)
+	for i, win := range wins {
+		if other, ok := won[win.amount]; ok {
+			t.Fatalf("bidders %d and %d both won at %.2f", other, win.buyerID, win.amount)
+		}
+		won[win.amount] = win.buyerID
+
+		if i > 0 && win.amount < NextMinBid(DefaultBidIncrements, wins[i-1].amount) {
+			t.Fatalf("bid %.2f accepted over %.2f without a full increment", win.amount, wins[i-1].amount)
+		}
+	}
+
+	last := wins[len(wins)-1]
+	if auction.leader() != last.buyerID || auction.currentBid() != last.amount {
+		t.Fatalf("bidder %d leads at %.2f, want the last accepted bid: %d at %.2f",
+			auction.leader(), auction.currentBid(), last.buyerID, last.amount)
+	}
+}
+
+// TestPlaceBidConcurrent fires hundreds of parallel bids at one auction. It
+// needs TEST_DB_URL to point at a scratch database set up from sql/init.sql.
+func TestPlaceBidConcurrent(t *testing.T) {
+	connURL := os.Getenv("TEST_DB_URL")
+	if connURL == "" {
+		t.Skip("TEST_DB_URL is not set")
+	}
+
+	c := context.Background()
+	pool, err := pgxpool.New(c, connURL)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer pool.Close()
+	config.DB = pool
+
+	const (
+		bidders    = 25
+		goroutines = 300
+	)
+
+	run := time.Now().UnixNano()
+	newUser := func(name string) int {
+		var userID int
+		err := pool.QueryRow(c, `
+            INSERT INTO users (username, password, email, address, mobile_number)
+            VALUES ($1, 'x', $1 || '@example.com', 'Test Street', '0000000000')
+            RETURNING user_id
+        `, fmt.Sprintf("%s_%d", name, run)).Scan(&userID)
+		if err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+		return userID
+	}
+
+	sellerID := newUser("seller")
+	bidderIDs := make([]int, bidders)
+	for i := range bidderIDs {
+		bidderIDs[i] = newUser(fmt.Sprintf("bidder%d", i))
+	}
+
+	var auctionID, itemID int
+	err = pool.QueryRow(c, `
+        INSERT INTO items (seller_id, title, image_path, starting_bid)
+        VALUES ($1, 'Concurrency test item', 'test.png', 10)
+        RETURNING item_id
+    `, sellerID).Scan(&itemID)
+	if err != nil {
+		t.Fatalf("create item: %v", err)
+	}
+	err = pool.QueryRow(c, `
+        INSERT INTO auctions (item_id, start_time, end_time, auction_status)
+        VALUES ($1, NOW() - INTERVAL '1 minute', NOW() + INTERVAL '1 hour', 'open')
+        RETURNING auction_id
+    `, itemID).Scan(&auctionID)
+	if err != nil {
+		t.Fatalf("create auction: %v", err)
+	}
+
+	results := make([]BidResult, goroutines)
+	errs := make([]error, goroutines)
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// Every tenth bid is a proxy with a ceiling above the manual bids around it
+			automated := i%10 == 0
+			amount := 10 + float64(i)*1.5
+			if automated {
+				amount += 20
+			}
+			results[i], errs[i] = PlaceBid(c, auctionID, bidderIDs[i%bidders], amount, 1, automated)
+		}(i)
+	}
+	wg.Wait()
+
+	manualBids := make(map[int]bool)
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("bid %d failed: %v", i, err)
+		}
+		if results[i].Outcome != BidRejected && i%10 != 0 {
+			if manualBids[results[i].BidID] {
+				t.Fatalf("bid ID %d returned twice", results[i].BidID)
+			}
+			manualBids[results[i].BidID] = true
+		}
+	}
+
+	rows, err := pool.Query(c, `
+        SELECT bid_id, buyer_id, bid_amount::float8, is_automated
+        FROM bids WHERE auction_id = $1
+        ORDER BY bid_id
+    `, auctionID)
+	if err != nil {
+		t.Fatalf("read bids: %v", err)
+	}
+	defer rows.Close()
+
+	var lastAmount, topAmount float64
+	var topBidder, manualRows int
+	for rows.Next() {
+		var bidID, buyerID int
+		var amount float64
+		var automated bool
+		if err := rows.Scan(&bidID, &buyerID, &amount, &automated); err != nil {
+			t.Fatalf("scan bid: %v", err)
+		}
+		if amount < lastAmount {
+			t.Errorf("bid %d of %.2f came after a bid of %.2f", bidID, amount, lastAmount)
+		}
+		lastAmount = amount
+
+		// An equal amount can only follow when a proxy wins a tie, and then the later bid leads
+		if amount >= topAmount {
+			topAmount, topBidder = amount, buyerID
+		}
+		if !automated {
+			manualRows++
+			if !manualBids[bidID] {
+				t.Errorf("manual bid %d was recorded but not returned as accepted", bidID)
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("read bids: %v", err)
+	}
+
+	if manualRows != len(manualBids) {
+		t.Errorf("%d manual bids recorded, %d accepted", manualRows, len(manualBids))
+	}
+
+	var highestBid float64
+	var highestBidder int
+	err = pool.QueryRow(c,
+		"SELECT current_highest_bid::float8, current_highest_bidder FROM items WHERE item_id = $1",
+		itemID).Scan(&highestBid, &highestBidder)
+	if err != nil {
+		t.Fatalf("read item: %v", err)
+	}
+	if highestBid != topAmount || highestBidder != topBidder {
+		t.Errorf("item shows bidder %d at %.2f, top bid is bidder %d at %.2f",
+			highestBidder, highestBid, topBidder, topAmount)
+	}
+
+	// No two bidders may have been told they lead at the final price
+	for i, result := range results {
+		if result.Outcome != BidRejected && result.HighestBid == topAmount && result.HighestBidder != topBidder {
+			t.Errorf("bid %d was told bidder %d leads at %.2f, but bidder %d does",
+				i, result.HighestBidder, topAmount, topBidder)
+		}
+	}
+}
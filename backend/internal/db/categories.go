@@ -0,0 +1,194 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"Online-Auction-System/backend/config"
+	"Online-Auction-System/backend/internal/schema"
+)
+
+var (
+	// ErrCategoryNotFound is returned when a category does not exist
+	ErrCategoryNotFound = errors.New("category not found")
+	// ErrCategoryInUse is returned when deleting a category that still has subcategories or items
+	ErrCategoryInUse = errors.New("category has subcategories or items")
+	// ErrCategoryCycle is returned when a category would be moved under itself
+	ErrCategoryCycle = errors.New("category cannot be moved under itself")
+)
+
+// CategoryExists reports whether a category exists
+func CategoryExists(c context.Context, categoryID int) (bool, error) {
+	var exists bool
+	err := config.DB.QueryRow(c, "SELECT EXISTS(SELECT 1 FROM categories WHERE category_id = $1)", categoryID).Scan(&exists)
+	return exists, err
+}
+
+// CreateCategory adds a category under parentID, or at the top level if parentID is nil
+func CreateCategory(c context.Context, name string, parentID *int) (int, error) {
+	var categoryID int
+	err := config.DB.QueryRow(c,
+		"INSERT INTO categories (name, parent_id) VALUES ($1, $2) RETURNING category_id",
+		name, parentID).Scan(&categoryID)
+	return categoryID, err
+}
+
+// UpdateCategory renames a category and moves it under parentID
+func UpdateCategory(c context.Context, categoryID int, name string, parentID *int) error {
+	if parentID != nil {
+		var cycle bool
+		err := config.DB.QueryRow(c, `
+            WITH RECURSIVE subtree AS (
+                SELECT category_id FROM categories WHERE category_id = $1
+                UNION ALL
+                SELECT ch.category_id FROM categories ch JOIN subtree s ON ch.parent_id = s.category_id
+            )
+            SELECT EXISTS(SELECT 1 FROM subtree WHERE category_id = $2)
+        `, categoryID, *parentID).Scan(&cycle)
+		if err != nil {
+			return err
+		}
+		if cycle {
+			return ErrCategoryCycle
+		}
+	}
+
+	result, err := config.DB.Exec(c,
+		"UPDATE categories SET name = $2, parent_id = $3 WHERE category_id = $1",
+		categoryID, name, parentID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrCategoryNotFound
+	}
+	return nil
+}
+
+// DeleteCategory removes a category that has no subcategories and no items
+func DeleteCategory(c context.Context, categoryID int) error {
+	result, err := config.DB.Exec(c, `
+        DELETE FROM categories c
+        WHERE c.category_id = $1
+          AND NOT EXISTS (SELECT 1 FROM categories WHERE parent_id = c.category_id)
+          AND NOT EXISTS (SELECT 1 FROM items WHERE category_id = c.category_id)
+    `, categoryID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() > 0 {
+		return nil
+	}
+
+	exists, err := CategoryExists(c, categoryID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrCategoryNotFound
+	}
+	return ErrCategoryInUse
+}
+
+// GetCategoryTree returns the top-level categories with their subcategories
+// nested inside. Each category counts the open auctions in its subtree.
+func GetCategoryTree(c context.Context) ([]schema.CategoryResponse, error) {
+	rows, err := config.DB.Query(c, `
+        SELECT cat.category_id, cat.parent_id, cat.name,
+               (SELECT COUNT(*) FROM auctions a
+                JOIN items i ON a.item_id = i.item_id
+                WHERE i.category_id = cat.category_id AND a.auction_status = 'open')
+        FROM categories cat
+        ORDER BY cat.name
+    `)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []schema.CategoryResponse
+	for rows.Next() {
+		var category schema.CategoryResponse
+		if err := rows.Scan(&category.CategoryID, &category.ParentID, &category.Name, &category.OpenAuctions); err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	children := make(map[int][]schema.CategoryResponse)
+	var roots []schema.CategoryResponse
+	for _, category := range categories {
+		if category.ParentID == nil {
+			roots = append(roots, category)
+		} else {
+			children[*category.ParentID] = append(children[*category.ParentID], category)
+		}
+	}
+
+	for i := range roots {
+		buildCategorySubtree(&roots[i], children)
+	}
+	return roots, nil
+}
+
+// buildCategorySubtree attaches the children of category recursively and adds
+// their open auction counts to its own
+func buildCategorySubtree(category *schema.CategoryResponse, children map[int][]schema.CategoryResponse) {
+	category.Children = children[category.CategoryID]
+	for i := range category.Children {
+		buildCategorySubtree(&category.Children[i], children)
+		category.OpenAuctions += category.Children[i].OpenAuctions
+	}
+}
+
+// GetCategory returns a category with its subtree
+func GetCategory(c context.Context, categoryID int) (schema.CategoryResponse, error) {
+	tree, err := GetCategoryTree(c)
+	if err != nil {
+		return schema.CategoryResponse{}, err
+	}
+
+	if category, ok := findCategory(tree, categoryID); ok {
+		return category, nil
+	}
+	return schema.CategoryResponse{}, ErrCategoryNotFound
+}
+
+func findCategory(categories []schema.CategoryResponse, categoryID int) (schema.CategoryResponse, bool) {
+	for _, category := range categories {
+		if category.CategoryID == categoryID {
+			return category, true
+		}
+		if found, ok := findCategory(category.Children, categoryID); ok {
+			return found, true
+		}
+	}
+	return schema.CategoryResponse{}, false
+}
+
+// setItemCategory files an item under a category
+func setItemCategory(c context.Context, tx pgx.Tx, itemID, categoryID int) error {
+	_, err := tx.Exec(c, "UPDATE items SET category_id = $2 WHERE item_id = $1", itemID, categoryID)
+	return err
+}
+
+// setItemTags replaces the tags on an item
+func setItemTags(c context.Context, tx pgx.Tx, itemID int, tags []string) error {
+	if _, err := tx.Exec(c, "DELETE FROM item_tags WHERE item_id = $1", itemID); err != nil {
+		return err
+	}
+
+	for _, tag := range tags {
+		_, err := tx.Exec(c, "INSERT INTO item_tags (item_id, tag) VALUES ($1, $2) ON CONFLICT DO NOTHING", itemID, tag)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
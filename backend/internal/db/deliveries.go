@@ -0,0 +1,226 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"Online-Auction-System/backend/config"
+	"Online-Auction-System/backend/internal/schema"
+)
+
+// Delivery statuses. A delivery goes from pending to shipped to delivered, or
+// fails when its payment does. A disputed shipment is refunded or delivered
+// when the dispute is resolved.
+const (
+	DeliveryPending   = "pending"
+	DeliveryShipped   = "shipped"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+	DeliveryRefunded  = "refunded"
+)
+
+var (
+	// ErrDeliveryNotFound is returned when the delivery does not exist or the user is neither its buyer nor its seller
+	ErrDeliveryNotFound = errors.New("delivery not found")
+	// ErrDeliveryTransition is returned when a delivery is not in the state the change needs
+	ErrDeliveryTransition = errors.New("delivery cannot change to that status")
+)
+
+const deliveryColumns = `
+        d.delivery_id, d.transaction_id, t.auction_id, i.title, t.buyer_id, bu.username,
+        i.seller_id, su.username, d.delivery_status, d.carrier, d.tracking_number, d.shipped_at,
+        d.delivery_date, d.auto_confirmed, d.disputed_at, d.dispute_reason, d.resolved_at, d.created_at`
+
+const deliveryJoins = `
+        JOIN transactions t ON d.transaction_id = t.transaction_id
+        JOIN auctions a ON t.auction_id = a.auction_id
+        JOIN items i ON a.item_id = i.item_id
+        JOIN users bu ON t.buyer_id = bu.user_id
+        JOIN users su ON i.seller_id = su.user_id`
+
+func deliveryFields(delivery *schema.DeliveryResponse) []any {
+	return []any{
+		&delivery.DeliveryID, &delivery.TransactionID, &delivery.AuctionID, &delivery.Title,
+		&delivery.BuyerID, &delivery.BuyerName, &delivery.SellerID, &delivery.SellerName,
+		&delivery.Status, &delivery.Carrier, &delivery.TrackingNumber, &delivery.ShippedAt,
+		&delivery.DeliveredAt, &delivery.AutoConfirmed, &delivery.DisputedAt, &delivery.DisputeReason,
+		&delivery.ResolvedAt, &delivery.CreatedAt,
+	}
+}
+
+var deliverySorts = map[string]sortOrder{
+	SortNewest: {key: "d.created_at", keyType: "timestamp", id: "d.delivery_id", desc: true},
+	SortOldest: {key: "d.created_at", keyType: "timestamp", id: "d.delivery_id"},
+}
+
+// GetDeliveries retrieves a page of the deliveries a user is sending or
+// receiving, newest first
+func GetDeliveries(c context.Context, userID int, page schema.PageRequest) (schema.Page[schema.DeliveryResponse], error) {
+	query := listQuery{
+		columns: deliveryColumns,
+		from:    "deliveries d" + deliveryJoins + " WHERE (t.buyer_id = $1 OR i.seller_id = $1)",
+		args:    []any{userID},
+		sorts:   deliverySorts,
+		sort:    SortNewest,
+	}
+
+	return queryPage(c, query, page, func(rows pgx.Rows, extra ...any) (schema.DeliveryResponse, error) {
+		var delivery schema.DeliveryResponse
+		err := rows.Scan(append(deliveryFields(&delivery), extra...)...)
+		return delivery, err
+	})
+}
+
+// GetDisputes retrieves a page of the disputed shipments nobody has resolved
+// yet, newest first, for admins
+func GetDisputes(c context.Context, page schema.PageRequest) (schema.Page[schema.DeliveryResponse], error) {
+	query := listQuery{
+		columns: deliveryColumns,
+		from:    "deliveries d" + deliveryJoins + " WHERE d.delivery_status = 'shipped' AND d.disputed_at IS NOT NULL",
+		sorts:   deliverySorts,
+		sort:    SortNewest,
+	}
+
+	return queryPage(c, query, page, func(rows pgx.Rows, extra ...any) (schema.DeliveryResponse, error) {
+		var delivery schema.DeliveryResponse
+		err := rows.Scan(append(deliveryFields(&delivery), extra...)...)
+		return delivery, err
+	})
+}
+
+// GetDelivery retrieves a delivery that userID is the buyer or seller of. A
+// userID of 0 retrieves any delivery, for admins.
+func GetDelivery(c context.Context, deliveryID, userID int) (schema.DeliveryResponse, error) {
+	var delivery schema.DeliveryResponse
+	err := config.DB.QueryRow(c,
+		"SELECT "+deliveryColumns+" FROM deliveries d"+deliveryJoins+" WHERE d.delivery_id = $1 AND ($2 = 0 OR t.buyer_id = $2 OR i.seller_id = $2)",
+		deliveryID, userID).Scan(deliveryFields(&delivery)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return delivery, ErrDeliveryNotFound
+	}
+
+	return delivery, err
+}
+
+// ShipDelivery marks a pending delivery as shipped
+func ShipDelivery(c context.Context, deliveryID int, carrier, trackingNumber string) error {
+	return updateDelivery(c, `
+        UPDATE deliveries
+        SET delivery_status = 'shipped', carrier = $2, tracking_number = $3, shipped_at = CURRENT_TIMESTAMP
+        WHERE delivery_id = $1 AND delivery_status = 'pending'
+    `, deliveryID, carrier, trackingNumber)
+}
+
+// ConfirmDelivery marks a shipped delivery as delivered, which releases the
+// payment held in escrow to the seller. A buyer can confirm receipt even after
+// raising a dispute.
+func ConfirmDelivery(c context.Context, deliveryID int) error {
+	return updateDelivery(c, `
+        UPDATE deliveries
+        SET delivery_status = 'delivered', delivery_date = CURRENT_TIMESTAMP
+        WHERE delivery_id = $1 AND delivery_status = 'shipped'
+    `, deliveryID)
+}
+
+// DisputeDelivery records the buyer's dispute of a shipped delivery, which
+// stops it being confirmed automatically. A delivery can be disputed once.
+func DisputeDelivery(c context.Context, deliveryID int, reason string) error {
+	return updateDelivery(c, `
+        UPDATE deliveries
+        SET disputed_at = CURRENT_TIMESTAMP, dispute_reason = $2
+        WHERE delivery_id = $1 AND delivery_status = 'shipped' AND disputed_at IS NULL
+    `, deliveryID, reason)
+}
+
+// RefundDelivery resolves a dispute in the buyer's favour: the delivery is
+// refunded and its payment refunded, which returns the money to the provider
+// from escrow, or from the seller if it was paid out
+func RefundDelivery(c context.Context, deliveryID int) error {
+	tx, err := config.DB.Begin(c)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(c)
+
+	var transactionID int
+	err = tx.QueryRow(c, `
+        UPDATE deliveries
+        SET delivery_status = 'refunded', resolved_at = CURRENT_TIMESTAMP
+        WHERE delivery_id = $1 AND delivery_status = 'shipped' AND disputed_at IS NOT NULL
+        RETURNING transaction_id
+    `, deliveryID).Scan(&transactionID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrDeliveryTransition
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(c, `
+        UPDATE payments SET payment_status = 'refunded'
+        WHERE transaction_id = $1 AND payment_status = 'completed'
+    `, transactionID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(c)
+}
+
+// ReleaseDisputedDelivery resolves a dispute in the seller's favour by
+// marking the delivery delivered, which releases any escrow to the seller
+func ReleaseDisputedDelivery(c context.Context, deliveryID int) error {
+	return updateDelivery(c, `
+        UPDATE deliveries
+        SET delivery_status = 'delivered', delivery_date = CURRENT_TIMESTAMP, resolved_at = CURRENT_TIMESTAMP
+        WHERE delivery_id = $1 AND delivery_status = 'shipped' AND disputed_at IS NOT NULL
+    `, deliveryID)
+}
+
+// updateDelivery runs a conditional update of one delivery, returning
+// ErrDeliveryTransition if the delivery was not in the state it needs
+func updateDelivery(c context.Context, sql string, args ...any) error {
+	result, err := config.DB.Exec(c, sql, args...)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrDeliveryTransition
+	}
+	return nil
+}
+
+// AutoConfirmDeliveries marks as delivered the undisputed shipments sent more
+// than window ago and returns them
+func AutoConfirmDeliveries(c context.Context, window time.Duration) ([]schema.DeliveryResponse, error) {
+	rows, err := config.DB.Query(c, `
+        WITH confirmed AS (
+            UPDATE deliveries
+            SET delivery_status = 'delivered', delivery_date = CURRENT_TIMESTAMP, auto_confirmed = TRUE
+            WHERE delivery_status = 'shipped'
+              AND disputed_at IS NULL
+              AND shipped_at <= NOW() - make_interval(secs => $1)
+            RETURNING *
+        )
+        SELECT `+deliveryColumns+`
+        FROM confirmed d`+deliveryJoins,
+		window.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []schema.DeliveryResponse
+	for rows.Next() {
+		var delivery schema.DeliveryResponse
+		if err := rows.Scan(deliveryFields(&delivery)...); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
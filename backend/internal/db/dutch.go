@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"Online-Auction-System/backend/config"
+	"Online-Auction-System/backend/internal/schema"
+)
+
+// DutchPrice is the price on offer in a Dutch auction after a scheduled step
+type DutchPrice struct {
+	AuctionID int
+	Price     float64
+}
+
+// setDutchSchedule stores the price schedule of a Dutch auction and puts the
+// starting bid on offer
+func setDutchSchedule(c context.Context, tx pgx.Tx, auctionID int, settings schema.DutchSettings) error {
+	_, err := tx.Exec(c, `
+        UPDATE auctions a
+        SET dutch_floor_price = $2,
+            dutch_price_step = $3,
+            dutch_step_minutes = $4,
+            dutch_current_price = i.starting_bid
+        FROM items i
+        WHERE a.item_id = i.item_id AND a.auction_id = $1
+    `, auctionID, settings.FloorPrice, settings.PriceStep, settings.StepMinutes)
+	return err
+}
+
+// dutchScheduledPrice is the price a Dutch auction's schedule calls for now,
+// for auctions a and items i: the starting bid less one step for each full
+// interval since the start, never below the floor
+const dutchScheduledPrice = `
+        GREATEST(a.dutch_floor_price,
+                 i.starting_bid - a.dutch_price_step *
+                     FLOOR(EXTRACT(EPOCH FROM NOW() - a.start_time) / (a.dutch_step_minutes * 60)))`
+
+// StepDutchPrices moves every running Dutch auction to the price its schedule
+// calls for now. The price is derived from the start time rather than the
+// previous step, so a missed scheduler run cannot slow the descent. It returns
+// the auctions whose price changed.
+func StepDutchPrices(c context.Context) ([]DutchPrice, error) {
+	rows, err := config.DB.Query(c, `
+        UPDATE auctions a
+        SET dutch_current_price = s.price
+        FROM (
+            SELECT a.auction_id, `+dutchScheduledPrice+` AS price
+            FROM auctions a
+            JOIN items i ON a.item_id = i.item_id
+            WHERE a.auction_type = 'dutch'
+        ) s
+        WHERE a.auction_id = s.auction_id
+          AND a.auction_status = 'open'
+          AND a.start_time <= NOW()
+          AND a.end_time > NOW()
+          AND a.dutch_current_price IS DISTINCT FROM s.price
+        RETURNING a.auction_id, a.dutch_current_price
+    `)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var prices []DutchPrice
+	for rows.Next() {
+		var price DutchPrice
+		if err := rows.Scan(&price.AuctionID, &price.Price); err != nil {
+			return nil, err
+		}
+		prices = append(prices, price)
+	}
+
+	return prices, rows.Err()
+}
+
+// AcceptDutchPrice sells the item in a Dutch auction to buyerID at the price on
+// offer. The price is worked out from the schedule at the moment of purchase,
+// so a buyer never pays a step the scheduler has not caught up with yet. The
+// auction row is locked for the whole purchase, so when two buyers accept at
+// once the second sees a closed auction and is rejected. It returns the
+// transaction ID.
+func AcceptDutchPrice(c context.Context, auctionID, buyerID int) (BidResult, int, error) {
+	tx, err := config.DB.Begin(c)
+	if err != nil {
+		return BidResult{}, 0, err
+	}
+	defer tx.Rollback(c)
+
+	auction, err := lockAuction(c, tx, auctionID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return BidResult{}, 0, ErrAuctionNotFound
+	}
+	if err != nil {
+		return BidResult{}, 0, err
+	}
+
+	result := BidResult{Amount: auction.dutchPrice.Float64}
+
+	if auction.auctionType != AuctionTypeDutch {
+		return rejectBid(result, "Only Dutch auctions can be accepted at the current price"), 0, nil
+	}
+	if reason := validateBid(auction, buyerID); reason != "" {
+		return rejectBid(result, reason), 0, nil
+	}
+
+	return sellNow(c, tx, auctionID, buyerID, auction.dutchPrice.Float64, auction, result)
+}
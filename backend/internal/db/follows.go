@@ -0,0 +1,161 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"Online-Auction-System/backend/config"
+	"Online-Auction-System/backend/internal/schema"
+)
+
+// AlertEmail delivers follower alerts by email; followers can also pick AlertInApp
+const AlertEmail = "email"
+
+// NotificationNewListing is the type of in-app notification for a followed seller's new auction
+const NotificationNewListing = "new_listing"
+
+// ErrNotFollowing is returned when unfollowing a seller the user does not follow
+var ErrNotFollowing = errors.New("not following this seller")
+
+// FollowerAlert is a follower to email about a seller's new auction
+type FollowerAlert struct {
+	UserID   int
+	Username string
+	Email    string
+}
+
+// FollowSeller makes followerID follow sellerID, or changes how an existing
+// follower hears about new listings
+func FollowSeller(c context.Context, followerID, sellerID int, delivery string) error {
+	_, err := config.DB.Exec(c, `
+        INSERT INTO seller_follows (follower_id, seller_id, alert_delivery)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (follower_id, seller_id) DO UPDATE SET alert_delivery = EXCLUDED.alert_delivery
+    `, followerID, sellerID, delivery)
+	return err
+}
+
+// UnfollowSeller stops followerID following sellerID
+func UnfollowSeller(c context.Context, followerID, sellerID int) error {
+	result, err := config.DB.Exec(c,
+		"DELETE FROM seller_follows WHERE follower_id = $1 AND seller_id = $2",
+		followerID, sellerID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFollowing
+	}
+	return nil
+}
+
+// GetFollowedSellers returns the sellers a user follows, most recently followed first
+func GetFollowedSellers(c context.Context, followerID int) ([]schema.FollowedSellerResponse, error) {
+	rows, err := config.DB.Query(c, `
+        SELECT f.seller_id, u.username, f.alert_delivery, f.created_at
+        FROM seller_follows f
+        JOIN users u ON f.seller_id = u.user_id
+        WHERE f.follower_id = $1
+        ORDER BY f.created_at DESC
+    `, followerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sellers := []schema.FollowedSellerResponse{}
+	for rows.Next() {
+		var seller schema.FollowedSellerResponse
+		if err := rows.Scan(&seller.SellerID, &seller.SellerName, &seller.Delivery, &seller.FollowedAt); err != nil {
+			return nil, err
+		}
+		sellers = append(sellers, seller)
+	}
+
+	return sellers, rows.Err()
+}
+
+// NotifyFollowers tells the followers of an open auction's seller about it.
+// Followers who asked for in-app alerts get a notification; those who asked
+// for email are returned for the caller to send to.
+func NotifyFollowers(c context.Context, auctionID int) ([]FollowerAlert, error) {
+	rows, err := config.DB.Query(c, `
+        WITH followers AS (
+            SELECT f.follower_id, f.alert_delivery, fu.username, fu.email,
+                   su.username AS seller_name, i.title
+            FROM auctions a
+            JOIN items i ON a.item_id = i.item_id
+            JOIN users su ON i.seller_id = su.user_id
+            JOIN seller_follows f ON f.seller_id = i.seller_id
+            JOIN users fu ON f.follower_id = fu.user_id
+            WHERE a.auction_id = $1 AND a.auction_status = 'open'
+        ),
+        notified AS (
+            INSERT INTO notifications (user_id, notification_type, auction_id, message)
+            SELECT follower_id, $2, $1, seller_name || ' listed a new auction: ' || title
+            FROM followers
+            WHERE alert_delivery = 'in_app'
+        )
+        SELECT follower_id, username, email
+        FROM followers
+        WHERE alert_delivery = 'email'
+    `, auctionID, NotificationNewListing)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var alerts []FollowerAlert
+	for rows.Next() {
+		var alert FollowerAlert
+		if err := rows.Scan(&alert.UserID, &alert.Username, &alert.Email); err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, alert)
+	}
+
+	return alerts, rows.Err()
+}
+
+// GetSellerListings retrieves a page of a seller's open and scheduled
+// auctions, marking those viewerID is watching
+func GetSellerListings(c context.Context, viewerID, sellerID int, page schema.PageRequest) (schema.Page[schema.AuctionResponse], error) {
+	query := listQuery{
+		columns: auctionListColumns,
+		from: `
+        auctions a
+        JOIN items i ON a.item_id = i.item_id
+        JOIN users u ON i.seller_id = u.user_id
+        LEFT JOIN seller_reputation sr ON sr.seller_id = i.seller_id
+        WHERE i.seller_id = $1
+        AND a.auction_status IN ('scheduled', 'open')
+        AND ($2 = '' OR a.auction_status = $2)`,
+		args:  []any{sellerID, page.Status},
+		sorts: auctionSorts,
+		sort:  SortEndingSoon,
+	}
+
+	listings, err := queryPage(c, query, page, func(rows pgx.Rows, extra ...any) (schema.AuctionResponse, error) {
+		var auction schema.AuctionResponse
+		if err := rows.Scan(append(auctionListFields(&auction), extra...)...); err != nil {
+			return auction, err
+		}
+		maskSealedBids(&auction)
+		return auction, nil
+	})
+	if err != nil {
+		return listings, err
+	}
+
+	watching, err := watchedAuctions(c, viewerID, auctionIDs(listings.Items))
+	if err != nil {
+		return listings, err
+	}
+	for i := range listings.Items {
+		listings.Items[i].Watching = watching[listings.Items[i].AuctionID]
+	}
+
+	return listings, nil
+}
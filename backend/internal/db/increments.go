@@ -0,0 +1,102 @@
+package db
+
+import (
+	"context"
+	"math"
+
+	"github.com/jackc/pgx/v5"
+
+	"Online-Auction-System/backend/config"
+	"Online-Auction-System/backend/internal/schema"
+)
+
+// DefaultBidIncrements is the site-wide schedule used when a seller sets none
+var DefaultBidIncrements = []schema.BidIncrementTier{
+	{MinPrice: 0, Increment: 1},
+	{MinPrice: 100, Increment: 5},
+	{MinPrice: 1000, Increment: 25},
+	{MinPrice: 10000, Increment: 100},
+	{MinPrice: 100000, Increment: 1000},
+	{MinPrice: 1000000, Increment: 5000},
+}
+
+// querier is satisfied by both the connection pool and a transaction
+type querier interface {
+	Query(c context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// setBidIncrements stores the increment schedule for an auction, replacing any existing one
+func setBidIncrements(c context.Context, tx pgx.Tx, auctionID int, tiers []schema.BidIncrementTier) error {
+	_, err := tx.Exec(c, "DELETE FROM auction_bid_increments WHERE auction_id = $1", auctionID)
+	if err != nil {
+		return err
+	}
+
+	for _, tier := range tiers {
+		_, err = tx.Exec(c, `
+            INSERT INTO auction_bid_increments (auction_id, min_price, increment)
+            VALUES ($1, $2, $3)
+        `, auctionID, tier.MinPrice, tier.Increment)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetBidIncrements returns the increment schedule for an auction, falling back to the site default
+func GetBidIncrements(c context.Context, auctionID int) ([]schema.BidIncrementTier, error) {
+	return queryBidIncrements(c, config.DB, auctionID)
+}
+
+func queryBidIncrements(c context.Context, q querier, auctionID int) ([]schema.BidIncrementTier, error) {
+	rows, err := q.Query(c, `
+        SELECT min_price, increment
+        FROM auction_bid_increments
+        WHERE auction_id = $1
+        ORDER BY min_price ASC
+    `, auctionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tiers []schema.BidIncrementTier
+	for rows.Next() {
+		var tier schema.BidIncrementTier
+		if err := rows.Scan(&tier.MinPrice, &tier.Increment); err != nil {
+			return nil, err
+		}
+		tiers = append(tiers, tier)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(tiers) == 0 {
+		return DefaultBidIncrements, nil
+	}
+	return tiers, nil
+}
+
+// IncrementFor returns the increment that applies at the given price
+func IncrementFor(tiers []schema.BidIncrementTier, price float64) float64 {
+	increment := DefaultBidIncrements[0].Increment
+	for _, tier := range tiers {
+		if price < tier.MinPrice {
+			break
+		}
+		increment = tier.Increment
+	}
+	return increment
+}
+
+// NextMinBid returns the lowest amount a new bid may have when the price stands at current
+func NextMinBid(tiers []schema.BidIncrementTier, current float64) float64 {
+	return roundCents(current + IncrementFor(tiers, current))
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,108 @@
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+
+	"Online-Auction-System/backend/config"
+	"Online-Auction-System/backend/internal/schema"
+)
+
+// GetSellerBalance returns what a seller has been paid and what is still held
+// in escrow for their sales. Entries are written by triggers: a hold or payout
+// when a payment completes, a release when its delivery is confirmed and a
+// refund when a dispute is settled in the buyer's favour.
+func GetSellerBalance(c context.Context, sellerID int) (schema.BalanceResponse, error) {
+	var balance schema.BalanceResponse
+	err := config.DB.QueryRow(c, `
+        SELECT
+            COALESCE((
+                SELECT SUM(CASE WHEN e.to_account_id = la.account_id THEN e.amount ELSE -e.amount END)
+                FROM ledger_accounts la
+                JOIN ledger_entries e ON la.account_id IN (e.from_account_id, e.to_account_id)
+                WHERE la.account_type = 'seller' AND la.user_id = $1
+            ), 0)::float8,
+            COALESCE((
+                SELECT SUM(h.amount)
+                FROM ledger_entries h
+                JOIN transactions t ON h.transaction_id = t.transaction_id
+                JOIN auctions a ON t.auction_id = a.auction_id
+                JOIN items i ON a.item_id = i.item_id
+                WHERE i.seller_id = $1
+                  AND h.entry_type = 'escrow_hold'
+                  AND NOT EXISTS (
+                      SELECT 1 FROM ledger_entries r
+                      WHERE r.transaction_id = h.transaction_id AND r.entry_type IN ('escrow_release', 'escrow_refund')
+                  )
+            ), 0)::float8
+    `, sellerID).Scan(&balance.Available, &balance.Held)
+
+	return balance, err
+}
+
+var ledgerSorts = map[string]sortOrder{
+	SortNewest: {key: "e.created_at", keyType: "timestamp", id: "e.entry_id", desc: true},
+	SortOldest: {key: "e.created_at", keyType: "timestamp", id: "e.entry_id"},
+}
+
+// GetLedgerEntries retrieves a page of the ledger entries for transactions a
+// user bought or sold in, newest first
+func GetLedgerEntries(c context.Context, userID int, page schema.PageRequest) (schema.Page[schema.LedgerEntryResponse], error) {
+	query := listQuery{
+		columns: `
+        e.entry_id, e.transaction_id, t.auction_id, i.title, e.entry_type,
+        fa.account_type, ta.account_type, e.amount, e.created_at`,
+		from: `
+        ledger_entries e
+        JOIN ledger_accounts fa ON e.from_account_id = fa.account_id
+        JOIN ledger_accounts ta ON e.to_account_id = ta.account_id
+        JOIN transactions t ON e.transaction_id = t.transaction_id
+        JOIN auctions a ON t.auction_id = a.auction_id
+        JOIN items i ON a.item_id = i.item_id
+        WHERE (t.buyer_id = $1 OR i.seller_id = $1)`,
+		args:  []any{userID},
+		sorts: ledgerSorts,
+		sort:  SortNewest,
+	}
+
+	return queryPage(c, query, page, func(rows pgx.Rows, extra ...any) (schema.LedgerEntryResponse, error) {
+		var entry schema.LedgerEntryResponse
+		err := rows.Scan(append([]any{
+			&entry.EntryID, &entry.TransactionID, &entry.AuctionID, &entry.Title, &entry.Type,
+			&entry.FromAccount, &entry.ToAccount, &entry.Amount, &entry.CreatedAt,
+		}, extra...)...)
+		return entry, err
+	})
+}
+
+// GetLedgerAccounts returns every ledger account with its balance, for auditing
+func GetLedgerAccounts(c context.Context) (schema.LedgerAuditResponse, error) {
+	rows, err := config.DB.Query(c, `
+        SELECT la.account_id, la.account_type, la.user_id, u.username,
+               COALESCE(SUM(CASE WHEN e.to_account_id = la.account_id THEN e.amount ELSE -e.amount END), 0)::float8,
+               COALESCE(SUM(SUM(CASE WHEN e.to_account_id = la.account_id THEN e.amount ELSE -e.amount END)) OVER (), 0)::float8
+        FROM ledger_accounts la
+        LEFT JOIN users u ON la.user_id = u.user_id
+        LEFT JOIN ledger_entries e ON la.account_id IN (e.from_account_id, e.to_account_id)
+        GROUP BY la.account_id, u.username
+        ORDER BY la.account_id
+    `)
+	if err != nil {
+		return schema.LedgerAuditResponse{}, err
+	}
+	defer rows.Close()
+
+	audit := schema.LedgerAuditResponse{Accounts: []schema.LedgerAccountResponse{}}
+	for rows.Next() {
+		var account schema.LedgerAccountResponse
+		err := rows.Scan(&account.AccountID, &account.Type, &account.UserID, &account.Username,
+			&account.Balance, &audit.Total)
+		if err != nil {
+			return audit, err
+		}
+		audit.Accounts = append(audit.Accounts, account)
+	}
+
+	return audit, rows.Err()
+}
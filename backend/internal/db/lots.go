@@ -0,0 +1,66 @@
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+
+	"Online-Auction-System/backend/config"
+	"Online-Auction-System/backend/internal/schema"
+)
+
+// setLotItems stores the pieces bundled into a lot, in the order given
+func setLotItems(c context.Context, tx pgx.Tx, itemID int, lotItems []schema.LotItem) error {
+	for position, lotItem := range lotItems {
+		imagePaths := lotItem.ImagePaths
+		if imagePaths == nil {
+			imagePaths = []string{}
+		}
+
+		_, err := tx.Exec(c, `
+            INSERT INTO lot_items (item_id, position, title, description, image_paths)
+            VALUES ($1, $2, $3, $4, $5)
+        `, itemID, position, lotItem.Title, lotItem.Description, imagePaths)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetLotItems returns the pieces of a lot, or nothing if the item is not a lot
+func GetLotItems(c context.Context, itemID int) ([]schema.LotItem, error) {
+	lotItems, err := getLotItemsForItems(c, []int{itemID})
+	return lotItems[itemID], err
+}
+
+// getLotItemsForItems returns the pieces of every lot among itemIDs, keyed by item
+func getLotItemsForItems(c context.Context, itemIDs []int) (map[int][]schema.LotItem, error) {
+	lotItems := make(map[int][]schema.LotItem)
+	if len(itemIDs) == 0 {
+		return lotItems, nil
+	}
+
+	rows, err := config.DB.Query(c, `
+        SELECT item_id, title, COALESCE(description, ''), image_paths
+        FROM lot_items
+        WHERE item_id = ANY($1)
+        ORDER BY item_id, position
+    `, itemIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var itemID int
+		var lotItem schema.LotItem
+		if err := rows.Scan(&itemID, &lotItem.Title, &lotItem.Description, &lotItem.ImagePaths); err != nil {
+			return nil, err
+		}
+		lotItems[itemID] = append(lotItems[itemID], lotItem)
+	}
+
+	return lotItems, rows.Err()
+}
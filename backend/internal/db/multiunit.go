@@ -0,0 +1,269 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"Online-Auction-System/backend/config"
+	"Online-Auction-System/backend/internal/schema"
+)
+
+// Fill states of a bid in a multi-quantity auction
+const (
+	FillStatusFilled   = "filled"
+	FillStatusPartial  = "partial"
+	FillStatusUnfilled = "unfilled"
+)
+
+// UnitWinner is a bidder who receives units when a multi-quantity auction clears
+type UnitWinner struct {
+	BuyerID  int
+	Quantity int
+	Bid      float64
+}
+
+// unitBid is a bidder's standing per-unit bid in a multi-quantity auction
+type unitBid struct {
+	bidID    int
+	buyerID  int
+	price    float64
+	quantity int
+	placedAt time.Time
+}
+
+// loadUnitBids returns the bids on an auction in fill order: highest price
+// first, earlier bids first at the same price
+func loadUnitBids(c context.Context, q querier, auctionID int) ([]unitBid, error) {
+	rows, err := q.Query(c, `
+        SELECT bid_id, buyer_id, bid_amount, quantity, bid_time
+        FROM bids
+        WHERE auction_id = $1
+        ORDER BY bid_amount DESC, bid_time ASC, bid_id ASC
+    `, auctionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bids []unitBid
+	for rows.Next() {
+		var bid unitBid
+		if err := rows.Scan(&bid.bidID, &bid.buyerID, &bid.price, &bid.quantity, &bid.placedAt); err != nil {
+			return nil, err
+		}
+		bids = append(bids, bid)
+	}
+
+	return bids, rows.Err()
+}
+
+// allocateUnits fills quantity units from bids in order, so the last bid to get
+// units may be filled partially. It returns the units given to each bid and the
+// clearing price, which is the lowest winning bid and what every winner pays.
+func allocateUnits(bids []unitBid, quantity int) ([]int, float64) {
+	fills := make([]int, len(bids))
+	clearingPrice := 0.0
+	remaining := quantity
+	for i, bid := range bids {
+		if remaining == 0 {
+			break
+		}
+		fills[i] = min(bid.quantity, remaining)
+		remaining -= fills[i]
+		clearingPrice = bid.price
+	}
+	return fills, clearingPrice
+}
+
+// unitNextMinBid returns the lowest per-unit price that wins at least one unit.
+// Until demand covers the quantity on offer the starting bid is enough.
+func unitNextMinBid(bids []unitBid, quantity int, startingBid float64, increments []schema.BidIncrementTier) float64 {
+	demand := 0
+	for _, bid := range bids {
+		demand += bid.quantity
+	}
+	if demand < quantity {
+		return startingBid
+	}
+	_, clearingPrice := allocateUnits(bids, quantity)
+	return max(startingBid, NextMinBid(increments, clearingPrice))
+}
+
+// fillStatus describes how much of a bid is currently filled
+func fillStatus(quantity, filled int) string {
+	switch {
+	case filled == 0:
+		return FillStatusUnfilled
+	case filled < quantity:
+		return FillStatusPartial
+	}
+	return FillStatusFilled
+}
+
+// placeMultiUnitBid records or revises the bidder's per-unit bid in a
+// multi-quantity auction. A revision may not lower the price or the quantity.
+// The item tracks the clearing price and the top bidder, and bidders pushed out
+// of the winning set are reported in the result.
+func placeMultiUnitBid(c context.Context, tx pgx.Tx, auctionID, buyerID int, amount float64, quantity int, auction lockedAuction) (BidResult, error) {
+	result := BidResult{Amount: amount, Quantity: quantity, HighestBid: auction.currentBid(), HighestBidder: auction.leader()}
+
+	if quantity < 1 || quantity > auction.quantity {
+		return rejectBid(result, fmt.Sprintf("Quantity must be between 1 and %d", auction.quantity)), nil
+	}
+
+	bids, err := loadUnitBids(c, tx, auctionID)
+	if err != nil {
+		return BidResult{}, err
+	}
+
+	var own *unitBid
+	others := make([]unitBid, 0, len(bids))
+	for i := range bids {
+		if bids[i].buyerID == buyerID {
+			own = &bids[i]
+		} else {
+			others = append(others, bids[i])
+		}
+	}
+
+	result.NextMinBid = unitNextMinBid(others, auction.quantity, auction.startingBid, auction.increments)
+	if own != nil && (amount < own.price || quantity < own.quantity) {
+		return rejectBid(result, "Revised bids cannot lower the price or the quantity"), nil
+	}
+	if amount < result.NextMinBid {
+		return rejectBid(result, fmt.Sprintf("Bid amount must be at least %.2f per unit", result.NextMinBid)), nil
+	}
+
+	fillsBefore, _ := allocateUnits(bids, auction.quantity)
+	winningBefore := make(map[int]float64)
+	for i, bid := range bids {
+		if fillsBefore[i] > 0 {
+			winningBefore[bid.buyerID] = bid.price
+		}
+	}
+
+	if own != nil {
+		result.BidID = own.bidID
+		result.Revised = true
+		_, err = tx.Exec(c, `
+            UPDATE bids
+            SET bid_amount = $2, quantity = $3, bid_time = CURRENT_TIMESTAMP
+            WHERE bid_id = $1
+        `, own.bidID, amount, quantity)
+	} else {
+		err = tx.QueryRow(c, `
+            INSERT INTO bids (auction_id, buyer_id, bid_amount, quantity)
+            VALUES ($1, $2, $3, $4)
+            RETURNING bid_id
+        `, auctionID, buyerID, amount, quantity).Scan(&result.BidID)
+	}
+	if err != nil {
+		return BidResult{}, err
+	}
+
+	bids, err = loadUnitBids(c, tx, auctionID)
+	if err != nil {
+		return BidResult{}, err
+	}
+
+	fills, clearingPrice := allocateUnits(bids, auction.quantity)
+	result.Displaced = make(map[int]float64)
+	for i, bid := range bids {
+		if bid.buyerID == buyerID {
+			result.Filled = fills[i]
+		} else if _, ok := winningBefore[bid.buyerID]; ok && fills[i] == 0 {
+			result.Displaced[bid.buyerID] = bid.price
+		}
+	}
+
+	if err := setHighestBid(c, tx, auctionID, auction.itemID, bids[0].buyerID, clearingPrice); err != nil {
+		return BidResult{}, err
+	}
+
+	result.HighestBid = clearingPrice
+	result.HighestBidder = bids[0].buyerID
+	result.NextMinBid = unitNextMinBid(bids, auction.quantity, auction.startingBid, auction.increments)
+	result.Outcome = BidAccepted
+
+	result.Extended, result.EndTime, err = applySoftClose(c, tx, auctionID, buyerID)
+	if err != nil {
+		return BidResult{}, err
+	}
+
+	if err := tx.Commit(c); err != nil {
+		return BidResult{}, err
+	}
+
+	return result, nil
+}
+
+// GetUnitNextMinBid returns the lowest per-unit price that currently wins a unit
+// in a multi-quantity auction
+func GetUnitNextMinBid(c context.Context, auction schema.AuctionResponse) (float64, error) {
+	bids, err := loadUnitBids(c, config.DB, auction.AuctionID)
+	if err != nil {
+		return 0, err
+	}
+
+	increments, err := GetBidIncrements(c, auction.AuctionID)
+	if err != nil {
+		return 0, err
+	}
+
+	return unitNextMinBid(bids, auction.Quantity, auction.StartingBid, increments), nil
+}
+
+// GetUnitWinners clears an ended multi-quantity auction. It returns the winning
+// bidders with the units each receives and the uniform price per unit.
+func GetUnitWinners(c context.Context, auction schema.AuctionResponse) ([]UnitWinner, float64, error) {
+	bids, err := loadUnitBids(c, config.DB, auction.AuctionID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	fills, clearingPrice := allocateUnits(bids, auction.Quantity)
+
+	var winners []UnitWinner
+	for i, bid := range bids {
+		if fills[i] > 0 {
+			winners = append(winners, UnitWinner{BuyerID: bid.buyerID, Quantity: fills[i], Bid: bid.price})
+		}
+	}
+
+	return winners, clearingPrice, nil
+}
+
+// setFillStatus fills in how much of each bid is winning for bids on
+// multi-quantity auctions. Bids on single-unit auctions are left untouched.
+func setFillStatus(c context.Context, bids []schema.BidResponse, quantities map[int]int) error {
+	fillsByAuction := make(map[int]map[int]int)
+	for i := range bids {
+		quantity := quantities[bids[i].AuctionID]
+		if quantity <= 1 {
+			continue
+		}
+
+		fills, ok := fillsByAuction[bids[i].AuctionID]
+		if !ok {
+			auctionBids, err := loadUnitBids(c, config.DB, bids[i].AuctionID)
+			if err != nil {
+				return err
+			}
+
+			allocated, _ := allocateUnits(auctionBids, quantity)
+			fills = make(map[int]int, len(auctionBids))
+			for j, bid := range auctionBids {
+				fills[bid.bidID] = allocated[j]
+			}
+			fillsByAuction[bids[i].AuctionID] = fills
+		}
+
+		bids[i].Filled = fills[bids[i].BidID]
+		bids[i].FillStatus = fillStatus(bids[i].Quantity, bids[i].Filled)
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"Online-Auction-System/backend/config"
+	"Online-Auction-System/backend/internal/schema"
+)
+
+// ErrNotificationNotFound is returned when the notification does not exist or belongs to someone else
+var ErrNotificationNotFound = errors.New("notification not found")
+
+var notificationSorts = map[string]sortOrder{
+	SortNewest: {key: "n.created_at", keyType: "timestamp", id: "n.notification_id", desc: true},
+}
+
+// GetNotifications retrieves a page of a user's in-app notifications, newest
+// first. With unreadOnly set, notifications already read are left out.
+func GetNotifications(c context.Context, userID int, unreadOnly bool, page schema.PageRequest) (schema.Page[schema.NotificationResponse], error) {
+	query := listQuery{
+		columns: `
+        n.notification_id, n.notification_type, COALESCE(n.auction_id, 0), n.message, n.is_read, n.created_at`,
+		from: `
+        notifications n
+        WHERE n.user_id = $1
+        AND (NOT $2 OR NOT n.is_read)`,
+		args:  []any{userID, unreadOnly},
+		sorts: notificationSorts,
+		sort:  SortNewest,
+	}
+
+	return queryPage(c, query, page, func(rows pgx.Rows, extra ...any) (schema.NotificationResponse, error) {
+		var notification schema.NotificationResponse
+		err := rows.Scan(append([]any{
+			&notification.NotificationID, &notification.Type, &notification.AuctionID,
+			&notification.Message, &notification.Read, &notification.CreatedAt,
+		}, extra...)...)
+		return notification, err
+	})
+}
+
+// MarkNotificationRead marks one of a user's notifications as read
+func MarkNotificationRead(c context.Context, userID, notificationID int) error {
+	result, err := config.DB.Exec(c,
+		"UPDATE notifications SET is_read = TRUE WHERE notification_id = $1 AND user_id = $2",
+		notificationID, userID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotificationNotFound
+	}
+	return nil
+}
+
+// MarkAllNotificationsRead marks every notification of a user as read
+func MarkAllNotificationsRead(c context.Context, userID int) error {
+	_, err := config.DB.Exec(c,
+		"UPDATE notifications SET is_read = TRUE WHERE user_id = $1 AND NOT is_read",
+		userID)
+	return err
+}
@@ -0,0 +1,248 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"Online-Auction-System/backend/config"
+	"Online-Auction-System/backend/internal/schema"
+)
+
+// Second-chance offer states
+const (
+	OfferPending  = "pending"
+	OfferAccepted = "accepted"
+	OfferDeclined = "declined"
+	OfferExpired  = "expired"
+)
+
+var (
+	// ErrNoSecondChance is returned when an auction cannot get a second-chance
+	// offer: it sold and was paid for, an offer is already open or was
+	// accepted, the item was relisted, or no bidder is left to ask
+	ErrNoSecondChance = errors.New("no second-chance offer possible")
+	// ErrOfferNotFound is returned when the offer does not exist or was made to someone else
+	ErrOfferNotFound = errors.New("offer not found")
+	// ErrOfferClosed is returned when the offer was already answered or has expired
+	ErrOfferClosed = errors.New("offer is no longer open")
+)
+
+const offerColumns = `
+        o.offer_id, o.auction_id, i.title, o.buyer_id, u.username, o.amount,
+        o.offer_status, o.created_at, o.expires_at, o.responded_at
+`
+
+const offerJoins = `
+        JOIN auctions a ON o.auction_id = a.auction_id
+        JOIN items i ON a.item_id = i.item_id
+        JOIN users u ON o.buyer_id = u.user_id
+`
+
+func scanOffer(row pgx.Row) (schema.SecondChanceOfferResponse, error) {
+	var offer schema.SecondChanceOfferResponse
+	err := row.Scan(
+		&offer.OfferID, &offer.AuctionID, &offer.Title, &offer.BuyerID, &offer.BuyerName, &offer.Amount,
+		&offer.Status, &offer.CreatedAt, &offer.ExpiresAt, &offer.RespondedAt,
+	)
+	return offer, err
+}
+
+// CreateSecondChanceOffer offers the item of an unsold single-unit auction to
+// the next bidder in line at their highest bid. Bidders are taken in bid order,
+// skipping anyone already offered the item and a winner who did not pay.
+func CreateSecondChanceOffer(c context.Context, auctionID int, expiresAt time.Time) (schema.SecondChanceOfferResponse, error) {
+	tx, err := config.DB.Begin(c)
+	if err != nil {
+		return schema.SecondChanceOfferResponse{}, err
+	}
+	defer tx.Rollback(c)
+
+	var eligible bool
+	err = tx.QueryRow(c, `
+        SELECT a.auction_status = 'closed'
+           AND i.quantity = 1
+           AND (a.close_reason = $2 OR EXISTS (
+               SELECT 1 FROM payments p
+               JOIN transactions t ON p.transaction_id = t.transaction_id
+               WHERE t.auction_id = a.auction_id AND p.payment_status = 'failed'
+           ))
+           AND NOT EXISTS (
+               SELECT 1 FROM auctions newer
+               WHERE newer.item_id = a.item_id AND newer.auction_id > a.auction_id
+           )
+           AND NOT EXISTS (
+               SELECT 1 FROM second_chance_offers o
+               WHERE o.auction_id = a.auction_id
+                 AND (o.offer_status = 'accepted' OR (o.offer_status = 'pending' AND o.expires_at > NOW()))
+           )
+        FROM auctions a
+        JOIN items i ON a.item_id = i.item_id
+        WHERE a.auction_id = $1
+        FOR UPDATE OF a
+    `, auctionID, CloseReasonReserveNotMet).Scan(&eligible)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return schema.SecondChanceOfferResponse{}, ErrAuctionNotFound
+	}
+	if err != nil {
+		return schema.SecondChanceOfferResponse{}, err
+	}
+	if !eligible {
+		return schema.SecondChanceOfferResponse{}, ErrNoSecondChance
+	}
+
+	var offerID int
+	err = tx.QueryRow(c, `
+        INSERT INTO second_chance_offers (auction_id, buyer_id, amount, expires_at)
+        SELECT b.auction_id, b.buyer_id, MAX(b.bid_amount), $2
+        FROM bids b
+        WHERE b.auction_id = $1
+          AND b.buyer_id NOT IN (SELECT buyer_id FROM second_chance_offers WHERE auction_id = $1)
+          AND b.buyer_id NOT IN (SELECT buyer_id FROM transactions WHERE auction_id = $1)
+        GROUP BY b.auction_id, b.buyer_id
+        ORDER BY MAX(b.bid_amount) DESC, MIN(b.bid_time) ASC
+        LIMIT 1
+        RETURNING offer_id
+    `, auctionID, expiresAt).Scan(&offerID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return schema.SecondChanceOfferResponse{}, ErrNoSecondChance
+	}
+	if err != nil {
+		return schema.SecondChanceOfferResponse{}, err
+	}
+
+	offer, err := scanOffer(tx.QueryRow(c, "SELECT"+offerColumns+"FROM second_chance_offers o"+offerJoins+"WHERE o.offer_id = $1", offerID))
+	if err != nil {
+		return schema.SecondChanceOfferResponse{}, err
+	}
+
+	return offer, tx.Commit(c)
+}
+
+// RespondToSecondChanceOffer accepts or declines an open offer made to buyerID.
+// Accepting sells the item at the offered amount, closes the auction as sold
+// and creates the transaction, whose ID is returned. The auction is locked
+// first, as RelistAuction and CreateSecondChanceOffer do, so an offer cannot
+// be accepted once its item has been relisted or sold another way.
+func RespondToSecondChanceOffer(c context.Context, offerID, buyerID int, accept bool) (schema.SecondChanceOfferResponse, int, error) {
+	tx, err := config.DB.Begin(c)
+	if err != nil {
+		return schema.SecondChanceOfferResponse{}, 0, err
+	}
+	defer tx.Rollback(c)
+
+	var open, relisted, sold bool
+	err = tx.QueryRow(c, `
+        SELECT o.expires_at > NOW(),
+               EXISTS (
+                   SELECT 1 FROM auctions newer
+                   WHERE newer.item_id = a.item_id AND newer.auction_id > a.auction_id
+               ),
+               EXISTS (
+                   SELECT 1 FROM transactions t
+                   WHERE t.auction_id = a.auction_id
+                     AND NOT EXISTS (
+                         SELECT 1 FROM payments p
+                         WHERE p.transaction_id = t.transaction_id AND p.payment_status = 'failed'
+                     )
+               )
+        FROM second_chance_offers o
+        JOIN auctions a ON o.auction_id = a.auction_id
+        WHERE o.offer_id = $1 AND o.buyer_id = $2
+        FOR UPDATE OF a
+    `, offerID, buyerID).Scan(&open, &relisted, &sold)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return schema.SecondChanceOfferResponse{}, 0, ErrOfferNotFound
+	}
+	if err != nil {
+		return schema.SecondChanceOfferResponse{}, 0, err
+	}
+
+	offer, err := scanOffer(tx.QueryRow(c,
+		"SELECT"+offerColumns+"FROM second_chance_offers o"+offerJoins+"WHERE o.offer_id = $1 FOR UPDATE OF o",
+		offerID))
+	if err != nil {
+		return offer, 0, err
+	}
+	if offer.Status != OfferPending || !open || (accept && (relisted || sold)) {
+		return offer, 0, ErrOfferClosed
+	}
+
+	offer.Status = OfferDeclined
+	if accept {
+		offer.Status = OfferAccepted
+	}
+
+	err = tx.QueryRow(c, `
+        UPDATE second_chance_offers
+        SET offer_status = $2, responded_at = NOW()
+        WHERE offer_id = $1
+        RETURNING responded_at
+    `, offerID, offer.Status).Scan(&offer.RespondedAt)
+	if err != nil {
+		return offer, 0, err
+	}
+
+	var transactionID int
+	if accept {
+		_, err = tx.Exec(c, `
+            UPDATE items i
+            SET current_highest_bid = $2, current_highest_bidder = $3
+            FROM auctions a
+            WHERE a.item_id = i.item_id AND a.auction_id = $1
+        `, offer.AuctionID, offer.Amount, buyerID)
+		if err != nil {
+			return offer, 0, err
+		}
+
+		_, err = tx.Exec(c, "UPDATE auctions SET close_reason = $2 WHERE auction_id = $1", offer.AuctionID, CloseReasonSold)
+		if err != nil {
+			return offer, 0, err
+		}
+
+		err = tx.QueryRow(c, `
+            INSERT INTO transactions (auction_id, buyer_id, amount)
+            VALUES ($1, $2, $3)
+            RETURNING transaction_id
+        `, offer.AuctionID, buyerID, offer.Amount).Scan(&transactionID)
+		if err != nil {
+			return offer, 0, err
+		}
+	}
+
+	return offer, transactionID, tx.Commit(c)
+}
+
+// ExpireSecondChanceOffers marks pending offers past their expiry as expired
+func ExpireSecondChanceOffers(c context.Context) error {
+	_, err := config.DB.Exec(c, `
+        UPDATE second_chance_offers
+        SET offer_status = 'expired'
+        WHERE offer_status = 'pending' AND expires_at <= NOW()
+    `)
+	return err
+}
+
+// GetSecondChanceOffers returns the offers made to a buyer, newest first
+func GetSecondChanceOffers(c context.Context, buyerID int) ([]schema.SecondChanceOfferResponse, error) {
+	rows, err := config.DB.Query(c,
+		"SELECT"+offerColumns+"FROM second_chance_offers o"+offerJoins+"WHERE o.buyer_id = $1 ORDER BY o.created_at DESC",
+		buyerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var offers []schema.SecondChanceOfferResponse
+	for rows.Next() {
+		offer, err := scanOffer(rows)
+		if err != nil {
+			return nil, err
+		}
+		offers = append(offers, offer)
+	}
+
+	return offers, rows.Err()
+}
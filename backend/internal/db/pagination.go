@@ -0,0 +1,168 @@
+package db
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"Online-Auction-System/backend/config"
+	"Online-Auction-System/backend/internal/schema"
+)
+
+const (
+	// DefaultPageSize is used when a request does not set a limit
+	DefaultPageSize = 20
+	// MaxPageSize is the largest page a request may ask for
+	MaxPageSize = 100
+	// totalEstimateCap is where counting the matching rows stops
+	totalEstimateCap = 10000
+)
+
+var (
+	// ErrInvalidCursor is returned for a cursor that is malformed or belongs to a different sort
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrInvalidSort is returned for a sort the list does not offer
+	ErrInvalidSort = errors.New("invalid sort")
+)
+
+// Sorts offered by list endpoints. Not every list offers every sort.
+const (
+	SortEndingSoon = "ending_soon"
+	SortNewest     = "newest"
+	SortOldest     = "oldest"
+	SortPriceAsc   = "price_asc"
+	SortPriceDesc  = "price_desc"
+	SortBidCount   = "bid_count"
+	SortRelevance  = "relevance"
+)
+
+// sortOrder is a keyset ordering. Rows are ordered by key, then by the unique
+// id column, both in the same direction, so the last row of a page marks
+// exactly where the next page starts.
+type sortOrder struct {
+	key     string // SQL expression; must never be NULL
+	keyType string // PostgreSQL type of key, used to cast it back from a cursor
+	id      string // unique column breaking ties between equal keys
+	desc    bool
+}
+
+// listQuery is a paginated query: the columns of each row, and the FROM
+// clause that picks the rows, with args numbered from $1. from must end in a
+// WHERE clause so that the cursor condition can be appended to it.
+type listQuery struct {
+	columns string
+	from    string
+	args    []any
+	sorts   map[string]sortOrder
+	sort    string // default sort
+}
+
+type cursor struct {
+	Sort string `json:"s"`
+	Key  string `json:"k"`
+	ID   int    `json:"i"`
+}
+
+func encodeCursor(cur cursor) string {
+	data, _ := json.Marshal(cur)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(encoded string) (cursor, error) {
+	var cur cursor
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cur, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, &cur); err != nil {
+		return cur, ErrInvalidCursor
+	}
+	return cur, nil
+}
+
+// queryPage runs a listQuery for one page. scan reads the columns of a row
+// followed by the extra destinations it is given, which receive the row's
+// position for the next cursor.
+func queryPage[T any](c context.Context, query listQuery, page schema.PageRequest, scan func(rows pgx.Rows, extra ...any) (T, error)) (schema.Page[T], error) {
+	var result schema.Page[T]
+
+	sortName := page.Sort
+	if sortName == "" {
+		sortName = query.sort
+	}
+	order, ok := query.sorts[sortName]
+	if !ok {
+		return result, ErrInvalidSort
+	}
+
+	limit := page.Limit
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	limit = min(limit, MaxPageSize)
+
+	err := config.DB.QueryRow(c,
+		fmt.Sprintf("SELECT COUNT(*) FROM (SELECT 1 FROM %s LIMIT %d) matching", query.from, totalEstimateCap),
+		query.args...).Scan(&result.TotalEstimate)
+	if err != nil {
+		return result, err
+	}
+
+	direction, comparison := "ASC", ">"
+	if order.desc {
+		direction, comparison = "DESC", "<"
+	}
+
+	args := query.args
+	where := ""
+	if page.Cursor != "" {
+		cur, err := decodeCursor(page.Cursor)
+		if err != nil {
+			return result, err
+		}
+		if cur.Sort != sortName {
+			return result, ErrInvalidCursor
+		}
+		where = fmt.Sprintf(" AND (%s, %s) %s ($%d::%s, $%d)",
+			order.key, order.id, comparison, len(args)+1, order.keyType, len(args)+2)
+		args = append(args[:len(args):len(args)], cur.Key, cur.ID)
+	}
+
+	rows, err := config.DB.Query(c, fmt.Sprintf(
+		"SELECT %s, (%s)::text, %s FROM %s%s ORDER BY %s %s, %s %s LIMIT %d",
+		query.columns, order.key, order.id, query.from, where,
+		order.key, direction, order.id, direction, limit+1,
+	), args...)
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+
+	var last cursor
+	for rows.Next() {
+		var key string
+		var id int
+		item, err := scan(rows, &key, &id)
+		if err != nil {
+			return result, err
+		}
+		if len(result.Items) == limit {
+			result.NextCursor = encodeCursor(last)
+			break
+		}
+		result.Items = append(result.Items, item)
+		last = cursor{Sort: sortName, Key: key, ID: id}
+	}
+	if err := rows.Err(); err != nil {
+		return result, err
+	}
+
+	if result.Items == nil {
+		result.Items = []T{}
+	}
+	return result, nil
+}
@@ -0,0 +1,239 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"Online-Auction-System/backend/config"
+	"Online-Auction-System/backend/internal/schema"
+)
+
+// Payment statuses
+const (
+	PaymentPending   = "pending"
+	PaymentCompleted = "completed"
+	PaymentFailed    = "failed"
+	PaymentRefunded  = "refunded"
+)
+
+var (
+	// ErrPaymentNotFound is returned when the payment does not exist or belongs to someone else
+	ErrPaymentNotFound = errors.New("payment not found")
+	// ErrPaymentClosed is returned when changing a payment that is no longer pending
+	ErrPaymentClosed = errors.New("payment is no longer pending")
+	// ErrPaymentOverdue is returned when completing a payment after its deadline
+	ErrPaymentOverdue = errors.New("payment overdue")
+)
+
+// PaymentRequest is a new request for a buyer to pay for a transaction
+type PaymentRequest struct {
+	PaymentID int
+	Amount    float64
+	DueAt     time.Time
+}
+
+// CreatePaymentRequest asks the buyer of a transaction to pay its amount
+// within window. Amounts of at least escrowThreshold are held in escrow once
+// paid. A transaction that already has a payment request keeps it, and a
+// PaymentID of 0 is returned.
+func CreatePaymentRequest(c context.Context, transactionID int, window time.Duration, escrowThreshold float64) (PaymentRequest, error) {
+	var request PaymentRequest
+	err := config.DB.QueryRow(c, `
+        INSERT INTO payments (transaction_id, amount, due_at, escrowed)
+        SELECT transaction_id, amount, NOW() + make_interval(secs => $2), amount >= $3
+        FROM transactions
+        WHERE transaction_id = $1
+        ON CONFLICT (transaction_id) DO NOTHING
+        RETURNING payment_id, amount, due_at
+    `, transactionID, window.Seconds(), escrowThreshold).Scan(&request.PaymentID, &request.Amount, &request.DueAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return PaymentRequest{}, nil
+	}
+
+	return request, err
+}
+
+const paymentColumns = `
+        p.payment_id, p.transaction_id, t.auction_id, i.title, p.amount, p.payment_method,
+        p.provider_reference, p.payment_status, p.escrowed, p.created_at, p.due_at, p.payment_date`
+
+const paymentJoins = `
+        payments p
+        JOIN transactions t ON p.transaction_id = t.transaction_id
+        JOIN auctions a ON t.auction_id = a.auction_id
+        JOIN items i ON a.item_id = i.item_id`
+
+func paymentFields(payment *schema.PaymentResponse) []any {
+	return []any{
+		&payment.PaymentID, &payment.TransactionID, &payment.AuctionID, &payment.Title, &payment.Amount,
+		&payment.Method, &payment.ProviderReference, &payment.Status, &payment.Escrowed, &payment.CreatedAt,
+		&payment.DueAt, &payment.PaidAt,
+	}
+}
+
+var paymentSorts = map[string]sortOrder{
+	SortNewest: {key: "p.created_at", keyType: "timestamp", id: "p.payment_id", desc: true},
+	SortOldest: {key: "p.created_at", keyType: "timestamp", id: "p.payment_id"},
+}
+
+// GetPayments retrieves a page of the payments a buyer owes or has made, newest first
+func GetPayments(c context.Context, buyerID int, page schema.PageRequest) (schema.Page[schema.PaymentResponse], error) {
+	query := listQuery{
+		columns: paymentColumns,
+		from:    paymentJoins + " WHERE t.buyer_id = $1",
+		args:    []any{buyerID},
+		sorts:   paymentSorts,
+		sort:    SortNewest,
+	}
+
+	return queryPage(c, query, page, func(rows pgx.Rows, extra ...any) (schema.PaymentResponse, error) {
+		var payment schema.PaymentResponse
+		err := rows.Scan(append(paymentFields(&payment), extra...)...)
+		return payment, err
+	})
+}
+
+// GetPayment retrieves one of a buyer's payments
+func GetPayment(c context.Context, paymentID, buyerID int) (schema.PaymentResponse, error) {
+	var payment schema.PaymentResponse
+	err := config.DB.QueryRow(c,
+		"SELECT "+paymentColumns+" FROM "+paymentJoins+" WHERE p.payment_id = $1 AND t.buyer_id = $2",
+		paymentID, buyerID).Scan(paymentFields(&payment)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return payment, ErrPaymentNotFound
+	}
+
+	return payment, err
+}
+
+// SetPaymentMethod records the method a buyer chose for a pending payment and
+// the provider charge opened for it, replacing any earlier choice
+func SetPaymentMethod(c context.Context, paymentID int, method, provider, reference string) error {
+	result, err := config.DB.Exec(c, `
+        UPDATE payments
+        SET payment_method = $2, provider = $3, provider_reference = $4
+        WHERE payment_id = $1 AND payment_status = 'pending' AND due_at > NOW()
+    `, paymentID, method, provider, reference)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrPaymentClosed
+	}
+	return nil
+}
+
+// CompletePayment marks a pending payment as paid through the
+// finalize_transaction procedure, whose triggers hold the money in escrow or pay
+// it out to the seller, and opens a pending delivery for the seller to ship. It
+// returns the new delivery's ID, or 0 if the transaction already had one. A
+// payment past its deadline cannot be completed.
+func CompletePayment(c context.Context, paymentID int) (int, error) {
+	tx, err := config.DB.Begin(c)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(c)
+
+	var transactionID int
+	var onTime bool
+	err = tx.QueryRow(c, `
+        SELECT transaction_id, due_at > NOW() FROM payments
+        WHERE payment_id = $1 AND payment_status = 'pending'
+        FOR UPDATE
+    `, paymentID).Scan(&transactionID, &onTime)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrPaymentClosed
+	}
+	if err != nil {
+		return 0, err
+	}
+	if !onTime {
+		return 0, ErrPaymentOverdue
+	}
+
+	if _, err = tx.Exec(c, "CALL finalize_transaction($1)", transactionID); err != nil {
+		return 0, err
+	}
+
+	var deliveryID int
+	err = tx.QueryRow(c, `
+        INSERT INTO deliveries (transaction_id)
+        VALUES ($1)
+        ON CONFLICT (transaction_id) DO NOTHING
+        RETURNING delivery_id
+    `, transactionID).Scan(&deliveryID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return 0, err
+	}
+
+	return deliveryID, tx.Commit(c)
+}
+
+// UnpaidTransaction is a transaction whose buyer did not pay by the deadline
+type UnpaidTransaction struct {
+	TransactionID int
+	AuctionID     int
+	BuyerID       int
+	SellerID      int
+	Amount        float64
+}
+
+// FailOverduePayments fails every pending payment past its deadline. The
+// transaction's delivery is failed with it and the buyer gets a strike. Once
+// its payment has failed an auction counts as unsold, so the seller can relist
+// the item or offer it to a runner-up. The failed transactions are returned.
+func FailOverduePayments(c context.Context) ([]UnpaidTransaction, error) {
+	rows, err := config.DB.Query(c, `
+        WITH failed AS (
+            UPDATE payments
+            SET payment_status = 'failed'
+            WHERE payment_status = 'pending' AND due_at <= NOW()
+            RETURNING transaction_id, amount
+        ),
+        failed_deliveries AS (
+            UPDATE deliveries d
+            SET delivery_status = 'failed'
+            FROM failed f
+            WHERE d.transaction_id = f.transaction_id AND d.delivery_status = 'pending'
+        ),
+        missing_deliveries AS (
+            INSERT INTO deliveries (transaction_id, delivery_status)
+            SELECT f.transaction_id, 'failed'
+            FROM failed f
+            WHERE NOT EXISTS (SELECT 1 FROM deliveries d WHERE d.transaction_id = f.transaction_id)
+        ),
+        strikes AS (
+            INSERT INTO buyer_strikes (user_id, transaction_id)
+            SELECT t.buyer_id, t.transaction_id
+            FROM failed f
+            JOIN transactions t ON f.transaction_id = t.transaction_id
+            ON CONFLICT (transaction_id) DO NOTHING
+        )
+        SELECT f.transaction_id, t.auction_id, t.buyer_id, i.seller_id, f.amount
+        FROM failed f
+        JOIN transactions t ON f.transaction_id = t.transaction_id
+        JOIN auctions a ON t.auction_id = a.auction_id
+        JOIN items i ON a.item_id = i.item_id
+    `)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var unpaid []UnpaidTransaction
+	for rows.Next() {
+		var transaction UnpaidTransaction
+		err := rows.Scan(&transaction.TransactionID, &transaction.AuctionID, &transaction.BuyerID,
+			&transaction.SellerID, &transaction.Amount)
+		if err != nil {
+			return nil, err
+		}
+		unpaid = append(unpaid, transaction)
+	}
+
+	return unpaid, rows.Err()
+}
@@ -2,6 +2,9 @@ package db
 
 import (
 	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
 
 	"Online-Auction-System/backend/config"
 	"Online-Auction-System/backend/internal/schema"
@@ -20,6 +23,47 @@ func GetUserProfile(c context.Context, userID int) (schema.ProfileResponse, erro
 	return profile, err
 }
 
+// ErrUserNotFound is returned when a user does not exist
+var ErrUserNotFound = errors.New("user not found")
+
+// GetPublicProfile retrieves what anyone may see of a user: no contact details,
+// but their reputation as a seller, their strikes for unpaid wins, their
+// follower count and whether viewerID follows them. Active listings are left
+// for the caller to fill in.
+func GetPublicProfile(c context.Context, viewerID, userID int) (schema.PublicProfileResponse, error) {
+	var profile schema.PublicProfileResponse
+	err := config.DB.QueryRow(c, `
+        SELECT u.user_id, u.username, u.created_at,`+reputationColumns+`,
+               (SELECT COUNT(*) FROM buyer_strikes bs WHERE bs.user_id = u.user_id),
+               (SELECT COUNT(*) FROM seller_follows f WHERE f.seller_id = u.user_id),
+               EXISTS(SELECT 1 FROM seller_follows f WHERE f.seller_id = u.user_id AND f.follower_id = $2)
+        FROM users u
+        LEFT JOIN seller_reputation sr ON sr.seller_id = u.user_id
+        WHERE u.user_id = $1
+    `, userID, viewerID).Scan(
+		&profile.UserID, &profile.Username, &profile.CreatedAt,
+		&profile.Reputation.AverageRating, &profile.Reputation.ReviewCount,
+		&profile.Reputation.PositivePercent, &profile.Reputation.CompletedSales,
+		&profile.UnpaidStrikes, &profile.FollowerCount, &profile.Following,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return profile, ErrUserNotFound
+	}
+	return profile, err
+}
+
+// RefreshStaleReputations recomputes seller reputations not refreshed for a
+// day, so that reviews drop out of the 12-month window even for sellers with
+// no new sales or reviews
+func RefreshStaleReputations(c context.Context) error {
+	_, err := config.DB.Exec(c, `
+        SELECT refresh_seller_reputation(seller_id)
+        FROM seller_reputation
+        WHERE updated_at <= NOW() - INTERVAL '1 day'
+    `)
+	return err
+}
+
 // UpdateUserProfile updates user profile information
 func UpdateUserProfile(c context.Context, userID int, profile schema.ProfileUpdate) error {
 	_, err := config.DB.Exec(c, `
@@ -33,78 +77,77 @@ func UpdateUserProfile(c context.Context, userID int, profile schema.ProfileUpda
 	return err
 }
 
-// GetSoldItems retrieves items sold by a specific user
-func GetSoldItems(c context.Context, sellerID int) ([]schema.TransactionResponse, error) {
-	rows, err := config.DB.Query(c, `
-        SELECT t.transaction_id, a.auction_id, i.title, i.current_highest_bid as price, 
-		t.transaction_date, COALESCE(r.rating, 0) as review
-        FROM transactions t
+// transactionSorts are the orders sold and bought history can be sorted in
+var transactionSorts = map[string]sortOrder{
+	SortNewest:    {key: "t.transaction_date", keyType: "timestamp", id: "t.transaction_id", desc: true},
+	SortOldest:    {key: "t.transaction_date", keyType: "timestamp", id: "t.transaction_id"},
+	SortPriceDesc: {key: "t.amount", keyType: "numeric", id: "t.transaction_id", desc: true},
+	SortPriceAsc:  {key: "t.amount", keyType: "numeric", id: "t.transaction_id"},
+}
+
+const transactionColumns = `
+        t.transaction_id, a.auction_id, i.item_id, i.title, t.quantity, t.amount as price,
+        t.transaction_date, COALESCE(r.rating, 0) as review`
+
+const transactionJoins = `
+        transactions t
         JOIN auctions a ON t.auction_id = a.auction_id
         JOIN items i ON a.item_id = i.item_id
-        LEFT JOIN reviews r ON t.transaction_id = r.transaction_id
-        WHERE i.seller_id = $1
-        ORDER BY t.transaction_date DESC
-    `, sellerID)
+        LEFT JOIN reviews r ON t.transaction_id = r.transaction_id AND r.reviewer_role = 'buyer'`
 
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
+// GetSoldItems retrieves a page of the items sold by a specific user
+func GetSoldItems(c context.Context, sellerID int, page schema.PageRequest) (schema.Page[schema.TransactionResponse], error) {
+	return getTransactions(c, listQuery{
+		columns: transactionColumns,
+		from:    transactionJoins + " WHERE i.seller_id = $1",
+		args:    []any{sellerID},
+		sorts:   transactionSorts,
+		sort:    SortNewest,
+	}, page)
+}
+
+// GetBoughtItems retrieves a page of the items bought by a specific user
+func GetBoughtItems(c context.Context, buyerID int, page schema.PageRequest) (schema.Page[schema.TransactionResponse], error) {
+	return getTransactions(c, listQuery{
+		columns: transactionColumns,
+		from:    transactionJoins + " WHERE t.buyer_id = $1",
+		args:    []any{buyerID},
+		sorts:   transactionSorts,
+		sort:    SortNewest,
+	}, page)
+}
 
-	var transactions []schema.TransactionResponse
-	for rows.Next() {
+// getTransactions reads a page of sold or bought history and lists the pieces
+// of any lots on it
+func getTransactions(c context.Context, query listQuery, page schema.PageRequest) (schema.Page[schema.TransactionResponse], error) {
+	var itemIDs []int
+	transactions, err := queryPage(c, query, page, func(rows pgx.Rows, extra ...any) (schema.TransactionResponse, error) {
 		var transaction schema.TransactionResponse
-		err := rows.Scan(
+		var itemID int
+		err := rows.Scan(append([]any{
 			&transaction.TransactionID,
 			&transaction.AuctionID,
+			&itemID,
 			&transaction.Title,
+			&transaction.Quantity,
 			&transaction.Price,
 			&transaction.Date,
 			&transaction.Review,
-		)
-		if err != nil {
-			return nil, err
-		}
-		transactions = append(transactions, transaction)
+		}, extra...)...)
+		itemIDs = append(itemIDs, itemID)
+		return transaction, err
+	})
+	if err != nil {
+		return transactions, err
 	}
 
-	return transactions, rows.Err()
-}
-
-// GetBoughtItems retrieves items bought by a specific user
-func GetBoughtItems(c context.Context, buyerID int) ([]schema.TransactionResponse, error) {
-	rows, err := config.DB.Query(c, `
-        SELECT t.transaction_id, a.auction_id, i.title, i.current_highest_bid as price,
-        t.transaction_date, COALESCE(r.rating, 0) as review
-        FROM transactions t
-        JOIN auctions a ON t.auction_id = a.auction_id
-        JOIN items i ON a.item_id = i.item_id
-        LEFT JOIN reviews r ON t.transaction_id = r.transaction_id
-        WHERE i.current_highest_bidder = $1
-        ORDER BY t.transaction_date DESC
-    `, buyerID)
-
+	lotItems, err := getLotItemsForItems(c, itemIDs)
 	if err != nil {
-		return nil, err
+		return transactions, err
 	}
-	defer rows.Close()
-
-	var transactions []schema.TransactionResponse
-	for rows.Next() {
-		var transaction schema.TransactionResponse
-		err := rows.Scan(
-			&transaction.TransactionID,
-			&transaction.AuctionID,
-			&transaction.Title,
-			&transaction.Price,
-			&transaction.Date,
-			&transaction.Review,
-		)
-		if err != nil {
-			return nil, err
-		}
-		transactions = append(transactions, transaction)
+	for i := range transactions.Items {
+		transactions.Items[i].LotItems = lotItems[itemIDs[i]]
 	}
 
-	return transactions, rows.Err()
+	return transactions, nil
 }
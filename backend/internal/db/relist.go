@@ -0,0 +1,165 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"Online-Auction-System/backend/config"
+)
+
+// ErrNotRelistable is returned when an auction cannot be relisted: it is still
+// running, its item sold and was paid for, some units of a multi-quantity
+// auction were paid for, a second-chance offer is open or was accepted, or the
+// item has been relisted already
+var ErrNotRelistable = errors.New("auction cannot be relisted")
+
+// relistState is what decides whether a closed auction's item can go back on
+// sale, read with the auction row locked
+type relistState struct {
+	status        string
+	closeReason   string
+	quantity      int
+	paymentFailed bool // a winner's payment failed
+	relisted      bool // the item already has a newer auction
+	offerTaken    bool // a second-chance offer is open or was accepted
+}
+
+// relistable reports whether the auction is unsold and its item free to list
+// again. A failed payment only frees a single item: on a multi-quantity
+// auction the other winners may have paid for their units, so relisting the
+// whole quantity would sell those units twice.
+func (s relistState) relistable() bool {
+	if s.status != "closed" || s.relisted || s.offerTaken {
+		return false
+	}
+	if s.closeReason == CloseReasonNoBids || s.closeReason == CloseReasonReserveNotMet {
+		return true
+	}
+	return s.paymentFailed && s.quantity == 1
+}
+
+// RelistAuction creates a new auction for the item of an unsold auction. An
+// auction is unsold if it closed without bids or below the reserve, or if the
+// payment for its single item failed. The new auction keeps the old one's
+// format, increments, soft-close and Dutch settings; the old auction and its
+// bids are left as they were. A startingBid of 0 keeps the item's starting
+// bid. When automatic is true one automatic relisting is used up. It returns
+// the new auction ID.
+func RelistAuction(c context.Context, auctionID int, startTime, endTime time.Time, startingBid float64, automatic bool) (int, error) {
+	tx, err := config.DB.Begin(c)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(c)
+
+	var itemID, autoRelist int
+	var state relistState
+	err = tx.QueryRow(c, `
+        SELECT a.item_id, a.auto_relist_remaining, a.auction_status, COALESCE(a.close_reason, ''), i.quantity,
+               EXISTS (
+                   SELECT 1 FROM payments p
+                   JOIN transactions t ON p.transaction_id = t.transaction_id
+                   WHERE t.auction_id = a.auction_id AND p.payment_status = 'failed'
+               ),
+               EXISTS (
+                   SELECT 1 FROM auctions newer
+                   WHERE newer.item_id = a.item_id AND newer.auction_id > a.auction_id
+               ),
+               EXISTS (
+                   SELECT 1 FROM second_chance_offers o
+                   WHERE o.auction_id = a.auction_id
+                     AND (o.offer_status = 'accepted' OR (o.offer_status = 'pending' AND o.expires_at > NOW()))
+               )
+        FROM auctions a
+        JOIN items i ON a.item_id = i.item_id
+        WHERE a.auction_id = $1
+        FOR UPDATE OF a
+    `, auctionID).Scan(&itemID, &autoRelist, &state.status, &state.closeReason, &state.quantity,
+		&state.paymentFailed, &state.relisted, &state.offerTaken)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrNotRelistable
+	}
+	if err != nil {
+		return 0, err
+	}
+	if !state.relistable() {
+		return 0, ErrNotRelistable
+	}
+
+	if automatic {
+		if autoRelist == 0 {
+			return 0, ErrNotRelistable
+		}
+		autoRelist--
+	}
+
+	_, err = tx.Exec(c, `
+        UPDATE auctions a
+        SET relisted_at = NOW(),
+            final_price = i.current_highest_bid,
+            final_bidder = i.current_highest_bidder
+        FROM items i
+        WHERE i.item_id = a.item_id AND a.auction_id = $1
+    `, auctionID)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec(c, `
+        UPDATE items
+        SET current_highest_bid = NULL,
+            current_highest_bidder = NULL,
+            starting_bid = COALESCE(NULLIF($2, 0), starting_bid)
+        WHERE item_id = $1
+    `, itemID, startingBid)
+	if err != nil {
+		return 0, err
+	}
+
+	auctionStatus := scheduledStatus(startTime)
+
+	var newAuctionID int
+	err = tx.QueryRow(c, `
+        INSERT INTO auctions (item_id, auction_type, start_time, end_time, auction_status,
+                              soft_close_window, soft_close_extension, soft_close_max_extensions,
+                              dutch_floor_price, dutch_price_step, dutch_step_minutes, dutch_current_price,
+                              auto_relist_remaining, relisted_from)
+        SELECT a.item_id, a.auction_type, $2, $3, $4,
+               a.soft_close_window, a.soft_close_extension, a.soft_close_max_extensions,
+               a.dutch_floor_price, a.dutch_price_step, a.dutch_step_minutes,
+               CASE WHEN a.auction_type = 'dutch' THEN i.starting_bid END,
+               $5, a.auction_id
+        FROM auctions a
+        JOIN items i ON a.item_id = i.item_id
+        WHERE a.auction_id = $1
+        RETURNING auction_id
+    `, auctionID, startTime, endTime, auctionStatus, autoRelist).Scan(&newAuctionID)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec(c, `
+        INSERT INTO auction_bid_increments (auction_id, min_price, increment)
+        SELECT $2, min_price, increment FROM auction_bid_increments WHERE auction_id = $1
+    `, auctionID, newAuctionID)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec(c, `
+        INSERT INTO auction_participants (auction_id, user_id, user_role)
+        SELECT $1, seller_id, 'seller' FROM items WHERE item_id = $2
+    `, newAuctionID, itemID)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(c); err != nil {
+		return 0, err
+	}
+
+	return newAuctionID, nil
+}
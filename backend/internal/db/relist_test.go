@@ -0,0 +1,46 @@
+package db
+
+import "testing"
+
+func TestRelistable(t *testing.T) {
+	closed := func(reason string, quantity int) relistState {
+		return relistState{status: "closed", closeReason: reason, quantity: quantity}
+	}
+	failed := func(quantity int) relistState {
+		state := closed(CloseReasonSold, quantity)
+		state.paymentFailed = true
+		return state
+	}
+
+	tests := []struct {
+		name  string
+		state relistState
+		want  bool
+	}{
+		{name: "no bids", state: closed(CloseReasonNoBids, 1), want: true},
+		{name: "reserve not met", state: closed(CloseReasonReserveNotMet, 1), want: true},
+		{name: "multi-unit with no bids", state: closed(CloseReasonNoBids, 5), want: true},
+		{name: "sold and paid", state: closed(CloseReasonSold, 1), want: false},
+		{name: "single item whose payment failed", state: failed(1), want: true},
+		{name: "multi-unit where one winner did not pay", state: failed(5), want: false},
+		{name: "still running", state: relistState{status: "open", quantity: 1}, want: false},
+		{
+			name:  "already relisted",
+			state: relistState{status: "closed", closeReason: CloseReasonNoBids, quantity: 1, relisted: true},
+			want:  false,
+		},
+		{
+			name:  "second-chance offer taken",
+			state: relistState{status: "closed", closeReason: CloseReasonSold, quantity: 1, paymentFailed: true, offerTaken: true},
+			want:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.state.relistable(); got != tt.want {
+				t.Fatalf("relistable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
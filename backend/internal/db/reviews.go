@@ -0,0 +1,201 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"Online-Auction-System/backend/config"
+	"Online-Auction-System/backend/internal/schema"
+)
+
+// Reviewer roles: who wrote a review in its transaction
+const (
+	RoleBuyer  = "buyer"
+	RoleSeller = "seller"
+)
+
+var (
+	// ErrNotTransactionParty is returned when the user neither bought nor sold in the auction
+	ErrNotTransactionParty = errors.New("not a party to this transaction")
+	// ErrBuyerRequired is returned when a seller with several buyers does not say which one they are reviewing
+	ErrBuyerRequired = errors.New("buyer must be given for an auction with several buyers")
+	// ErrSaleNotPaid is returned when reviewing a sale whose payment has not completed
+	ErrSaleNotPaid = errors.New("sale has not been paid")
+	// ErrReviewLocked is returned when editing a review after its edit window has closed
+	ErrReviewLocked = errors.New("review can no longer be edited")
+	// ErrReviewNotFound is returned when the review does not exist
+	ErrReviewNotFound = errors.New("review not found")
+	// ErrNotReviewee is returned when someone other than the reviewed user replies to a review
+	ErrNotReviewee = errors.New("only the reviewed user can reply")
+	// ErrAlreadyReplied is returned when a review already has a reply
+	ErrAlreadyReplied = errors.New("review already has a reply")
+)
+
+// Review is a review about to be written, with who wrote it about whom
+type Review struct {
+	TransactionID int
+	ReviewerID    int
+	RevieweeID    int
+	ReviewerRole  string
+	Rating        int
+	Comment       string
+}
+
+// GetReviewableTransaction works out which transaction of an auction userID
+// can review and whom they would be reviewing. The buyer reviews the seller;
+// the seller reviews a buyer, who must be named with buyerID when the auction
+// sold to more than one. A sale can only be reviewed once it is paid for, even
+// if the payment was later refunded after a dispute.
+func GetReviewableTransaction(c context.Context, auctionID, userID, buyerID int) (Review, error) {
+	rows, err := config.DB.Query(c, `
+        SELECT t.transaction_id, t.buyer_id, i.seller_id,
+               EXISTS(SELECT 1 FROM payments p
+                      WHERE p.transaction_id = t.transaction_id
+                        AND p.payment_status IN ('completed', 'refunded'))
+        FROM transactions t
+        JOIN auctions a ON t.auction_id = a.auction_id
+        JOIN items i ON a.item_id = i.item_id
+        WHERE t.auction_id = $1
+        AND (t.buyer_id = $2 OR (i.seller_id = $2 AND ($3 = 0 OR t.buyer_id = $3)))
+    `, auctionID, userID, buyerID)
+	if err != nil {
+		return Review{}, err
+	}
+	defer rows.Close()
+
+	var reviews []Review
+	var paid []bool
+	for rows.Next() {
+		var transactionID, buyer, seller int
+		var isPaid bool
+		if err := rows.Scan(&transactionID, &buyer, &seller, &isPaid); err != nil {
+			return Review{}, err
+		}
+
+		review := Review{TransactionID: transactionID, ReviewerID: userID}
+		if buyer == userID {
+			review.RevieweeID = seller
+			review.ReviewerRole = RoleBuyer
+		} else {
+			review.RevieweeID = buyer
+			review.ReviewerRole = RoleSeller
+		}
+		reviews = append(reviews, review)
+		paid = append(paid, isPaid)
+	}
+	if err := rows.Err(); err != nil {
+		return Review{}, err
+	}
+
+	switch len(reviews) {
+	case 0:
+		return Review{}, ErrNotTransactionParty
+	case 1:
+		if !paid[0] {
+			return Review{}, ErrSaleNotPaid
+		}
+		return reviews[0], nil
+	default:
+		return Review{}, ErrBuyerRequired
+	}
+}
+
+// SubmitReview writes a review, or edits the reviewer's earlier review of the
+// same transaction while it is still within the edit window. A trigger
+// refreshes the seller's cached reputation.
+func SubmitReview(c context.Context, review Review) (int, error) {
+	var reviewID int
+	err := config.DB.QueryRow(c, `
+        INSERT INTO reviews (transaction_id, reviewer_id, reviewee_id, reviewer_role, rating, comment)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        ON CONFLICT (transaction_id, reviewer_id)
+        DO UPDATE SET rating = EXCLUDED.rating, comment = EXCLUDED.comment, updated_at = CURRENT_TIMESTAMP
+        WHERE reviews.review_date > CURRENT_TIMESTAMP - make_interval(secs => $7)
+        RETURNING review_id
+    `, review.TransactionID, review.ReviewerID, review.RevieweeID, review.ReviewerRole,
+		review.Rating, review.Comment, config.ReviewEditWindow().Seconds()).Scan(&reviewID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrReviewLocked
+	}
+
+	return reviewID, err
+}
+
+// ReplyToReview adds the reviewed user's public reply to a review. Each review
+// can be replied to once.
+func ReplyToReview(c context.Context, reviewID, userID int, reply string) error {
+	result, err := config.DB.Exec(c, `
+        UPDATE reviews SET reply = $3, reply_date = CURRENT_TIMESTAMP
+        WHERE review_id = $1 AND reviewee_id = $2 AND reply IS NULL
+    `, reviewID, userID, reply)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() > 0 {
+		return nil
+	}
+
+	var revieweeID int
+	var replied bool
+	err = config.DB.QueryRow(c,
+		"SELECT reviewee_id, reply IS NOT NULL FROM reviews WHERE review_id = $1",
+		reviewID).Scan(&revieweeID, &replied)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return ErrReviewNotFound
+	case err != nil:
+		return err
+	case revieweeID != userID:
+		return ErrNotReviewee
+	default:
+		return ErrAlreadyReplied
+	}
+}
+
+var reviewSorts = map[string]sortOrder{
+	SortNewest: {key: "r.review_date", keyType: "timestamp", id: "r.review_id", desc: true},
+	SortOldest: {key: "r.review_date", keyType: "timestamp", id: "r.review_id"},
+}
+
+// GetUserReviews retrieves a page of the reviews written about a user, newest
+// first. role limits them to reviews the user received as a seller or as a
+// buyer; it may be empty for both.
+func GetUserReviews(c context.Context, userID int, role string, page schema.PageRequest) (schema.Page[schema.ReviewResponse], error) {
+	// Reviews received as a seller were written by buyers, and the other way round
+	reviewerRole := ""
+	switch role {
+	case RoleSeller:
+		reviewerRole = RoleBuyer
+	case RoleBuyer:
+		reviewerRole = RoleSeller
+	}
+
+	query := listQuery{
+		columns: `
+        r.review_id, t.auction_id, i.title, r.reviewer_id, u.username, r.reviewee_id, r.reviewer_role,
+        r.rating, r.comment, r.review_date, r.updated_at, r.reply, r.reply_date`,
+		from: `
+        reviews r
+        JOIN transactions t ON r.transaction_id = t.transaction_id
+        JOIN auctions a ON t.auction_id = a.auction_id
+        JOIN items i ON a.item_id = i.item_id
+        JOIN users u ON r.reviewer_id = u.user_id
+        WHERE r.reviewee_id = $1
+        AND ($2 = '' OR r.reviewer_role = $2)`,
+		args:  []any{userID, reviewerRole},
+		sorts: reviewSorts,
+		sort:  SortNewest,
+	}
+
+	return queryPage(c, query, page, func(rows pgx.Rows, extra ...any) (schema.ReviewResponse, error) {
+		var review schema.ReviewResponse
+		err := rows.Scan(append([]any{
+			&review.ReviewID, &review.AuctionID, &review.Title, &review.ReviewerID, &review.ReviewerName,
+			&review.RevieweeID, &review.ReviewerRole, &review.Rating, &review.Comment,
+			&review.Date, &review.UpdatedAt, &review.Reply, &review.ReplyDate,
+		}, extra...)...)
+		return review, err
+	})
+}
@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"github.com/jackc/pgx/v5"
+
+	"Online-Auction-System/backend/internal/schema"
+)
+
+// searchPrice is the price an auction is currently selling at: the Dutch
+// price, the starting bid while sealed bids are hidden, or the highest bid
+const searchPrice = `
+        CASE
+            WHEN a.auction_type = 'dutch' THEN COALESCE(a.dutch_current_price, i.starting_bid)
+            WHEN a.auction_type IN ('sealed_first_price', 'sealed_second_price') AND a.auction_status != 'closed' THEN i.starting_bid
+            ELSE COALESCE(` + auctionHighestBid + `, i.starting_bid)
+        END
+`
+
+// prefixQuery turns free text into a tsquery that requires every word, each
+// matched whole or as a prefix. Punctuation is dropped, so the result is always
+// valid tsquery syntax. It returns "" if the text has no words.
+func prefixQuery(text string) string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, len(words))
+	for i, word := range words {
+		terms[i] = strings.ToLower(word) + ":*"
+	}
+	return strings.Join(terms, " & ")
+}
+
+// searchSorts are the orders search results can be sorted in
+var searchSorts = map[string]sortOrder{
+	SortRelevance:  {key: "ts_rank_cd(i.search_vector, q)", keyType: "real", id: "a.auction_id", desc: true},
+	SortEndingSoon: auctionSorts[SortEndingSoon],
+	SortNewest:     auctionSorts[SortNewest],
+	SortPriceAsc:   auctionSorts[SortPriceAsc],
+	SortPriceDesc:  auctionSorts[SortPriceDesc],
+	SortBidCount:   auctionSorts[SortBidCount],
+}
+
+// SearchAuctions finds a page of published auctions matching a full-text
+// search, best matches first unless another sort is asked for, marking those
+// userID is watching
+func SearchAuctions(c context.Context, userID int, search schema.AuctionSearch, page schema.PageRequest) (schema.Page[schema.AuctionSearchResult], error) {
+	tsQuery := prefixQuery(search.Query)
+	if tsQuery == "" {
+		return schema.Page[schema.AuctionSearchResult]{Items: []schema.AuctionSearchResult{}}, nil
+	}
+
+	var endingBefore any
+	if !search.EndingBefore.IsZero() {
+		endingBefore = search.EndingBefore
+	}
+
+	query := listQuery{
+		columns: auctionListColumns + `,
+        ts_rank_cd(i.search_vector, q),
+        ts_headline('english', i.title, q, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true'),
+        ts_headline('english', COALESCE(i.description, ''), q, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=30, MinWords=10')`,
+		from: `
+        auctions a
+        JOIN items i ON a.item_id = i.item_id
+        JOIN users u ON i.seller_id = u.user_id
+        LEFT JOIN seller_reputation sr ON sr.seller_id = i.seller_id,
+             to_tsquery('english', $1) q
+        WHERE i.search_vector @@ q
+        AND a.auction_status NOT IN ('draft', 'deleted')
+        AND ($2 = '' OR a.auction_status = $2)
+        AND ($3::numeric = 0 OR ` + searchPrice + ` >= $3)
+        AND ($4::numeric = 0 OR ` + searchPrice + ` <= $4)
+        AND ($5::timestamp IS NULL OR a.end_time < $5)`,
+		args:  []any{tsQuery, page.Status, search.MinPrice, search.MaxPrice, endingBefore},
+		sorts: searchSorts,
+		sort:  SortRelevance,
+	}
+
+	results, err := queryPage(c, query, page, func(rows pgx.Rows, extra ...any) (schema.AuctionSearchResult, error) {
+		var result schema.AuctionSearchResult
+		fields := append(auctionListFields(&result.AuctionResponse),
+			&result.Rank, &result.TitleHighlight, &result.DescriptionHighlight)
+		if err := rows.Scan(append(fields, extra...)...); err != nil {
+			return result, err
+		}
+		maskSealedBids(&result.AuctionResponse)
+		return result, nil
+	})
+	if err != nil {
+		return results, err
+	}
+
+	ids := make([]int, len(results.Items))
+	for i, result := range results.Items {
+		ids[i] = result.AuctionID
+	}
+	watching, err := watchedAuctions(c, userID, ids)
+	if err != nil {
+		return results, err
+	}
+	for i := range results.Items {
+		results.Items[i].Watching = watching[results.Items[i].AuctionID]
+	}
+
+	return results, nil
+}
@@ -0,0 +1,236 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"Online-Auction-System/backend/config"
+	"Online-Auction-System/backend/internal/schema"
+)
+
+// How a user hears about new listings matching a saved search
+const (
+	AlertInApp       = "in_app"
+	AlertDailyDigest = "daily_digest"
+)
+
+// NotificationSavedSearch is the type of in-app notification for a saved-search match
+const NotificationSavedSearch = "saved_search"
+
+// ErrSavedSearchNotFound is returned when the saved search does not exist or belongs to someone else
+var ErrSavedSearchNotFound = errors.New("saved search not found")
+
+// DigestMatch is a new listing reported in a saved-search digest
+type DigestMatch struct {
+	SearchName string
+	AuctionID  int
+	Title      string
+	EndTime    time.Time
+}
+
+// SearchDigest is the daily email of new listings matching a user's saved searches
+type SearchDigest struct {
+	UserID   int
+	Username string
+	Email    string
+	Matches  []DigestMatch
+}
+
+const savedSearchColumns = `
+        search_id, name, keywords, category_id, COALESCE(max_price, 0), alert_delivery, created_at
+`
+
+func scanSavedSearch(row pgx.Row) (schema.SavedSearchResponse, error) {
+	var search schema.SavedSearchResponse
+	err := row.Scan(
+		&search.SearchID, &search.Name, &search.Keywords, &search.CategoryID,
+		&search.MaxPrice, &search.Delivery, &search.CreatedAt,
+	)
+	return search, err
+}
+
+// CountSavedSearches returns how many searches a user has saved
+func CountSavedSearches(c context.Context, userID int) (int, error) {
+	var count int
+	err := config.DB.QueryRow(c, "SELECT COUNT(*) FROM saved_searches WHERE user_id = $1", userID).Scan(&count)
+	return count, err
+}
+
+// CreateSavedSearch saves a search for a user. A zero MaxPrice means no limit.
+func CreateSavedSearch(c context.Context, userID int, search schema.SavedSearchRequest) (schema.SavedSearchResponse, error) {
+	return scanSavedSearch(config.DB.QueryRow(c, `
+        INSERT INTO saved_searches (user_id, name, keywords, ts_query, category_id, max_price, alert_delivery)
+        VALUES ($1, $2, $3, $4, $5, NULLIF($6, 0), $7)
+        RETURNING`+savedSearchColumns,
+		userID, search.Name, search.Keywords, prefixQuery(search.Keywords),
+		search.CategoryID, search.MaxPrice, search.Delivery))
+}
+
+// UpdateSavedSearch replaces the criteria and alert delivery of a saved search
+func UpdateSavedSearch(c context.Context, userID, searchID int, search schema.SavedSearchRequest) (schema.SavedSearchResponse, error) {
+	saved, err := scanSavedSearch(config.DB.QueryRow(c, `
+        UPDATE saved_searches
+        SET name = $3, keywords = $4, ts_query = $5, category_id = $6,
+            max_price = NULLIF($7, 0), alert_delivery = $8
+        WHERE search_id = $1 AND user_id = $2
+        RETURNING`+savedSearchColumns,
+		searchID, userID, search.Name, search.Keywords, prefixQuery(search.Keywords),
+		search.CategoryID, search.MaxPrice, search.Delivery))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return saved, ErrSavedSearchNotFound
+	}
+	return saved, err
+}
+
+// DeleteSavedSearch removes a saved search and its pending matches
+func DeleteSavedSearch(c context.Context, userID, searchID int) error {
+	result, err := config.DB.Exec(c,
+		"DELETE FROM saved_searches WHERE search_id = $1 AND user_id = $2",
+		searchID, userID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrSavedSearchNotFound
+	}
+	return nil
+}
+
+// GetSavedSearches returns a user's saved searches, newest first
+func GetSavedSearches(c context.Context, userID int) ([]schema.SavedSearchResponse, error) {
+	rows, err := config.DB.Query(c,
+		"SELECT"+savedSearchColumns+"FROM saved_searches WHERE user_id = $1 ORDER BY created_at DESC, search_id DESC",
+		userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	searches := []schema.SavedSearchResponse{}
+	for rows.Next() {
+		search, err := scanSavedSearch(rows)
+		if err != nil {
+			return nil, err
+		}
+		searches = append(searches, search)
+	}
+
+	return searches, rows.Err()
+}
+
+// MatchSavedSearches records the saved searches an auction matches once it is
+// open, and sends an in-app notification for those that ask for one. Searches
+// belonging to the seller are skipped, and an auction is matched to each
+// search only once. A category matches auctions in any of its subcategories.
+func MatchSavedSearches(c context.Context, auctionID int) error {
+	_, err := config.DB.Exec(c, `
+        WITH RECURSIVE ancestors AS (
+            SELECT cat.category_id, cat.parent_id
+            FROM categories cat
+            JOIN items i ON cat.category_id = i.category_id
+            JOIN auctions a ON a.item_id = i.item_id
+            WHERE a.auction_id = $1
+            UNION ALL
+            SELECT p.category_id, p.parent_id
+            FROM categories p
+            JOIN ancestors an ON p.category_id = an.parent_id
+        ),
+        matched AS (
+            INSERT INTO saved_search_matches (search_id, auction_id, notified_at)
+            SELECT s.search_id, a.auction_id,
+                   CASE WHEN s.alert_delivery = 'in_app' THEN NOW() END
+            FROM saved_searches s, auctions a
+            JOIN items i ON a.item_id = i.item_id
+            WHERE a.auction_id = $1
+              AND a.auction_status = 'open'
+              AND s.user_id != i.seller_id
+              AND (s.ts_query = '' OR i.search_vector @@ to_tsquery('english', s.ts_query))
+              AND (s.category_id IS NULL OR s.category_id IN (SELECT category_id FROM ancestors))
+              AND (s.max_price IS NULL OR `+searchPrice+` <= s.max_price)
+            ON CONFLICT DO NOTHING
+            RETURNING search_id, auction_id
+        )
+        INSERT INTO notifications (user_id, notification_type, auction_id, message)
+        SELECT s.user_id, $2, m.auction_id,
+               'New listing matching "' || s.name || '": ' || i.title
+        FROM matched m
+        JOIN saved_searches s ON m.search_id = s.search_id
+        JOIN auctions a ON m.auction_id = a.auction_id
+        JOIN items i ON a.item_id = i.item_id
+        WHERE s.alert_delivery = 'in_app'
+    `, auctionID, NotificationSavedSearch)
+	return err
+}
+
+// ClaimSavedSearchDigests collects the unreported matches of daily-digest
+// searches for users whose last digest went out at least a day ago, and marks
+// them as reported. Matches for auctions that are no longer open are dropped.
+func ClaimSavedSearchDigests(c context.Context) ([]SearchDigest, error) {
+	tx, err := config.DB.Begin(c)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(c)
+
+	rows, err := tx.Query(c, `
+        UPDATE saved_search_matches m
+        SET notified_at = NOW()
+        FROM saved_searches s, users u, auctions a, items i
+        WHERE m.search_id = s.search_id
+          AND s.user_id = u.user_id
+          AND m.auction_id = a.auction_id
+          AND a.item_id = i.item_id
+          AND m.notified_at IS NULL
+          AND s.alert_delivery = 'daily_digest'
+          AND (u.digest_sent_at IS NULL OR u.digest_sent_at <= NOW() - INTERVAL '1 day')
+        RETURNING u.user_id, u.username, u.email, a.auction_status, s.name, a.auction_id, i.title, a.end_time
+    `)
+	if err != nil {
+		return nil, err
+	}
+
+	var digests []SearchDigest
+	index := make(map[int]int)
+	for rows.Next() {
+		var digest SearchDigest
+		var match DigestMatch
+		var status string
+		err := rows.Scan(
+			&digest.UserID, &digest.Username, &digest.Email, &status,
+			&match.SearchName, &match.AuctionID, &match.Title, &match.EndTime,
+		)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if status != "open" {
+			continue
+		}
+
+		i, ok := index[digest.UserID]
+		if !ok {
+			i = len(digests)
+			index[digest.UserID] = i
+			digests = append(digests, digest)
+		}
+		digests[i].Matches = append(digests[i].Matches, match)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]int, len(digests))
+	for i, digest := range digests {
+		userIDs[i] = digest.UserID
+	}
+	_, err = tx.Exec(c, "UPDATE users SET digest_sent_at = NOW() WHERE user_id = ANY($1)", userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return digests, tx.Commit(c)
+}
@@ -6,37 +6,22 @@ import (
 	"Online-Auction-System/backend/config"
 )
 
-// CreateTransaction creates a transaction record for a completed auction
-func CreateTransaction(c context.Context, auctionID int) (int, error) {
-	var transactionID int
-	err := config.DB.QueryRow(c, `
-        INSERT INTO transactions (auction_id)
-        VALUES ($1)
-        RETURNING transaction_id
-    `, auctionID).Scan(&transactionID)
-
-	return transactionID, err
+// Sale is a buyer's purchase from a closing auction. Amount is the total the
+// buyer pays for Quantity units.
+type Sale struct {
+	BuyerID  int
+	Quantity int
+	Amount   float64
 }
 
-// GetTransactionByAuctionID gets transaction ID for an auction
-func GetTransactionByAuctionID(c context.Context, auctionID int) (int, error) {
+// GetTransactionByAuctionID gets the buyer's transaction ID for an auction. A
+// multi-quantity auction has one transaction per winning buyer.
+func GetTransactionByAuctionID(c context.Context, auctionID, buyerID int) (int, error) {
 	var transactionID int
 	err := config.DB.QueryRow(c, `
         SELECT transaction_id FROM transactions
-        WHERE auction_id = $1
-    `, auctionID).Scan(&transactionID)
+        WHERE auction_id = $1 AND buyer_id = $2
+    `, auctionID, buyerID).Scan(&transactionID)
 
 	return transactionID, err
 }
-
-// SubmitReview adds a review for a transaction
-func SubmitReview(c context.Context, transactionID int, rating int) error {
-	_, err := config.DB.Exec(c, `
-        INSERT INTO reviews (transaction_id, rating)
-        VALUES ($1, $2)
-        ON CONFLICT (transaction_id) 
-        DO UPDATE SET rating = EXCLUDED.rating, review_date = CURRENT_TIMESTAMP
-    `, transactionID, rating)
-
-	return err
-}
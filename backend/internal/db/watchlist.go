@@ -0,0 +1,136 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"Online-Auction-System/backend/config"
+	"Online-Auction-System/backend/internal/schema"
+)
+
+// ErrNotWatching is returned when removing an auction that is not on the watchlist
+var ErrNotWatching = errors.New("auction is not on the watchlist")
+
+// EndingSoonReminder is a watcher to remind that an auction is about to end
+type EndingSoonReminder struct {
+	AuctionID int
+	UserID    int
+	Username  string
+	Email     string
+}
+
+// AddToWatchlist stars an auction for a user. Adding an auction twice is not an error.
+func AddToWatchlist(c context.Context, userID, auctionID int) error {
+	_, err := config.DB.Exec(c, `
+        INSERT INTO watchlist (user_id, auction_id)
+        VALUES ($1, $2)
+        ON CONFLICT DO NOTHING
+    `, userID, auctionID)
+	return err
+}
+
+// RemoveFromWatchlist unstars an auction for a user
+func RemoveFromWatchlist(c context.Context, userID, auctionID int) error {
+	result, err := config.DB.Exec(c,
+		"DELETE FROM watchlist WHERE user_id = $1 AND auction_id = $2",
+		userID, auctionID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotWatching
+	}
+	return nil
+}
+
+// GetWatchlist retrieves a page of the auctions a user is watching, ending
+// soonest first unless another sort is asked for
+func GetWatchlist(c context.Context, userID int, page schema.PageRequest) (schema.Page[schema.AuctionResponse], error) {
+	query := listQuery{
+		columns: auctionListColumns,
+		from: `
+        watchlist w
+        JOIN auctions a ON w.auction_id = a.auction_id
+        JOIN items i ON a.item_id = i.item_id
+        JOIN users u ON i.seller_id = u.user_id
+        LEFT JOIN seller_reputation sr ON sr.seller_id = i.seller_id
+        WHERE w.user_id = $1
+        AND a.auction_status NOT IN ('draft', 'deleted')
+        AND ($2 = '' OR a.auction_status = $2)`,
+		args:  []any{userID, page.Status},
+		sorts: auctionSorts,
+		sort:  SortEndingSoon,
+	}
+
+	return queryPage(c, query, page, func(rows pgx.Rows, extra ...any) (schema.AuctionResponse, error) {
+		var auction schema.AuctionResponse
+		if err := rows.Scan(append(auctionListFields(&auction), extra...)...); err != nil {
+			return auction, err
+		}
+		maskSealedBids(&auction)
+		auction.Watching = true
+		return auction, nil
+	})
+}
+
+// watchedAuctions reports which of the given auctions userID is watching
+func watchedAuctions(c context.Context, userID int, auctionIDs []int) (map[int]bool, error) {
+	watching := make(map[int]bool)
+	if userID == 0 || len(auctionIDs) == 0 {
+		return watching, nil
+	}
+
+	rows, err := config.DB.Query(c,
+		"SELECT auction_id FROM watchlist WHERE user_id = $1 AND auction_id = ANY($2)",
+		userID, auctionIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var auctionID int
+		if err := rows.Scan(&auctionID); err != nil {
+			return nil, err
+		}
+		watching[auctionID] = true
+	}
+
+	return watching, rows.Err()
+}
+
+// ClaimEndingSoonReminders returns the watchers of open auctions ending within
+// lead who have not been reminded yet, and marks them as reminded so that each
+// watcher gets a single reminder per auction
+func ClaimEndingSoonReminders(c context.Context, lead time.Duration) ([]EndingSoonReminder, error) {
+	rows, err := config.DB.Query(c, `
+        UPDATE watchlist w
+        SET reminder_sent_at = NOW()
+        FROM auctions a, users u
+        WHERE w.auction_id = a.auction_id
+          AND w.user_id = u.user_id
+          AND w.reminder_sent_at IS NULL
+          AND a.auction_status = 'open'
+          AND a.end_time > NOW()
+          AND a.end_time <= NOW() + make_interval(secs => $1)
+        RETURNING w.auction_id, w.user_id, u.username, u.email
+    `, lead.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reminders []EndingSoonReminder
+	for rows.Next() {
+		var reminder EndingSoonReminder
+		if err := rows.Scan(&reminder.AuctionID, &reminder.UserID, &reminder.Username, &reminder.Email); err != nil {
+			return nil, err
+		}
+		reminders = append(reminders, reminder)
+	}
+
+	return reminders, rows.Err()
+}
@@ -18,8 +18,15 @@ import (
 type notifType string
 
 const (
-	NotificationOutbid     notifType = "outbid"
-	NotificationAuctionEnd notifType = "auction_end"
+	NotificationOutbid         notifType = "outbid"
+	NotificationAuctionEnd     notifType = "auction_end"
+	NotificationSecondChance   notifType = "second_chance"
+	NotificationEndingSoon     notifType = "ending_soon"
+	NotificationSearchDigest   notifType = "search_digest"
+	NotificationNewListing     notifType = "new_listing"
+	NotificationPaymentRequest notifType = "payment_request"
+	NotificationPaymentFailed  notifType = "payment_failed"
+	NotificationDeliveryUpdate notifType = "delivery_update"
 )
 
 // SendAuctionEmail sends an email notification related to auctions
@@ -50,6 +57,17 @@ func SendAuctionEmail(c context.Context, receiver string, notifType notifType, a
 		emailData[key] = val
 	}
 
+	return sendEmail(receiver, notifType, emailData)
+}
+
+// SendDigestEmail sends the daily email listing new auctions that match a
+// user's saved searches. data must hold "username" and "matches".
+func SendDigestEmail(receiver string, data map[string]interface{}) error {
+	return sendEmail(receiver, NotificationSearchDigest, data)
+}
+
+// sendEmail renders the templates of a notification type with data and mails the result
+func sendEmail(receiver string, notifType notifType, emailData map[string]interface{}) error {
 	subject, err := ParseTemplate(fmt.Sprintf("internal/templates/%s/subject.txt", notifType), emailData)
 	if err != nil {
 		return fmt.Errorf("failed to parse subject template: %w", err)
@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"Online-Auction-System/backend/internal/db"
 	"Online-Auction-System/backend/internal/helpers"
 )
 
@@ -30,3 +31,23 @@ func AuthMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// AdminMiddleware only lets administrators through. It must run after AuthMiddleware.
+func AdminMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := helpers.GetUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+			c.Abort()
+			return
+		}
+
+		user, err := db.GetUserByID(c, userID)
+		if err != nil || !user.IsAdmin {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Administrator access required"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
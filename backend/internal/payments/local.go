@@ -0,0 +1,59 @@
+package payments
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// LocalProvider is a fake provider for development and tests. It takes every
+// charge as paid unless told to decline it, and forgets charges on restart.
+type LocalProvider struct {
+	mu       sync.Mutex
+	next     int
+	declined map[string]bool
+	charges  map[string]Charge
+}
+
+// NewLocalProvider creates a LocalProvider with no charges
+func NewLocalProvider() *LocalProvider {
+	return &LocalProvider{
+		declined: make(map[string]bool),
+		charges:  make(map[string]Charge),
+	}
+}
+
+func (p *LocalProvider) Name() string {
+	return "local"
+}
+
+func (p *LocalProvider) CreateCharge(c context.Context, charge Charge) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.next++
+	reference := fmt.Sprintf("local_%d_%d", charge.PaymentID, p.next)
+	p.charges[reference] = charge
+	return reference, nil
+}
+
+func (p *LocalProvider) ConfirmCharge(c context.Context, reference string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.charges[reference]; !ok {
+		return ErrUnknownCharge
+	}
+	if p.declined[reference] {
+		return ErrChargeDeclined
+	}
+	return nil
+}
+
+// Decline makes confirming the charge with reference fail, as if the buyer never paid
+func (p *LocalProvider) Decline(reference string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.declined[reference] = true
+}
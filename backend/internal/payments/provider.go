@@ -0,0 +1,50 @@
+// Package payments holds the payment providers that collect what buyers owe.
+package payments
+
+import (
+	"context"
+	"errors"
+)
+
+// Payment methods a buyer can choose from
+const (
+	MethodCreditCard   = "credit_card"
+	MethodUPI          = "UPI"
+	MethodBankTransfer = "bank_transfer"
+)
+
+var (
+	// ErrChargeDeclined is returned when the provider refuses or has not received the payment
+	ErrChargeDeclined = errors.New("payment was declined")
+	// ErrUnknownCharge is returned when the provider has no charge with the given reference
+	ErrUnknownCharge = errors.New("unknown charge")
+)
+
+// Charge is an amount to collect from a buyer for one payment
+type Charge struct {
+	PaymentID int
+	BuyerID   int
+	Amount    float64
+	Method    string
+}
+
+// Provider collects payments. A charge is created when the buyer picks a
+// method and confirmed once they say they have paid.
+type Provider interface {
+	// Name identifies the provider in stored payments
+	Name() string
+	// CreateCharge starts collecting a charge and returns the provider's reference for it
+	CreateCharge(c context.Context, charge Charge) (string, error)
+	// ConfirmCharge checks that the charge with reference has been paid. It
+	// returns ErrChargeDeclined if it has not.
+	ConfirmCharge(c context.Context, reference string) error
+}
+
+// ValidMethod reports whether method is a payment method buyers can choose
+func ValidMethod(method string) bool {
+	switch method {
+	case MethodCreditCard, MethodUPI, MethodBankTransfer:
+		return true
+	}
+	return false
+}
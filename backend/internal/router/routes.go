@@ -32,13 +32,20 @@ func SetupRoutes(router *gin.Engine) {
 	auctionGroup.Use(middlewares.AuthMiddleware())
 	{
 		auctionGroup.GET("", controller.GetAuctionsHandler)
+		auctionGroup.GET("/search", controller.SearchAuctionsHandler)
 		auctionGroup.GET("/:id", controller.GetAuctionHandler)
 		auctionGroup.GET("/:id/bids", controller.GetBidsHandler)
 		auctionGroup.DELETE("/:id", controller.DeleteAuctionHandler)
+		auctionGroup.PUT("/:id", controller.UpdateAuctionHandler)
+		auctionGroup.POST("/:id/publish", controller.PublishAuctionHandler)
 		auctionGroup.PUT("/:id/update-end-time", controller.UpdateAuctionEndTimeHandler)
 		auctionGroup.POST("", controller.CreateAuctionHandler)
 		auctionGroup.POST("/:id/bid", controller.PlaceBidHandler)
 		auctionGroup.POST("/:id/automated-bid", controller.PlaceAutomatedBidHandler)
+		auctionGroup.POST("/:id/buy-now", controller.BuyNowHandler)
+		auctionGroup.POST("/:id/accept", controller.AcceptDutchPriceHandler)
+		auctionGroup.POST("/:id/relist", controller.RelistAuctionHandler)
+		auctionGroup.POST("/:id/second-chance", controller.CreateSecondChanceOfferHandler)
 		auctionGroup.POST("/upload", controller.UploadImageHandler)
 	}
 
@@ -58,11 +65,90 @@ func SetupRoutes(router *gin.Engine) {
 		profileGroup.GET("/bids", controller.GetUserBidsHandler)
 		profileGroup.GET("/sold", controller.GetUserSoldHandler)
 		profileGroup.GET("/bought", controller.GetUserBoughtHandler)
+		profileGroup.GET("/watchlist", controller.GetWatchlistHandler)
+		profileGroup.POST("/watchlist/:id", controller.AddToWatchlistHandler)
+		profileGroup.DELETE("/watchlist/:id", controller.RemoveFromWatchlistHandler)
+		profileGroup.GET("/following", controller.GetFollowedSellersHandler)
+		profileGroup.GET("/balance", controller.GetBalanceHandler)
+		profileGroup.GET("/ledger", controller.GetLedgerHandler)
+	}
+
+	offerGroup := router.Group("/api/offers")
+	offerGroup.Use(middlewares.AuthMiddleware())
+	{
+		offerGroup.GET("", controller.GetSecondChanceOffersHandler)
+		offerGroup.POST("/:id/accept", controller.AcceptSecondChanceOfferHandler)
+		offerGroup.POST("/:id/decline", controller.DeclineSecondChanceOfferHandler)
+	}
+
+	categoryGroup := router.Group("/api/categories")
+	categoryGroup.Use(middlewares.AuthMiddleware())
+	{
+		categoryGroup.GET("", controller.GetCategoriesHandler)
+		categoryGroup.GET("/:id", controller.GetCategoryHandler)
+	}
+
+	adminGroup := router.Group("/api/admin")
+	adminGroup.Use(middlewares.AuthMiddleware(), middlewares.AdminMiddleware())
+	{
+		adminGroup.POST("/categories", controller.CreateCategoryHandler)
+		adminGroup.PUT("/categories/:id", controller.UpdateCategoryHandler)
+		adminGroup.DELETE("/categories/:id", controller.DeleteCategoryHandler)
+		adminGroup.GET("/ledger", controller.GetLedgerAccountsHandler)
+		adminGroup.GET("/disputes", controller.GetDisputesHandler)
+		adminGroup.POST("/disputes/:id/resolve", controller.ResolveDisputeHandler)
+	}
+
+	userGroup := router.Group("/api/users")
+	userGroup.Use(middlewares.AuthMiddleware())
+	{
+		userGroup.GET("/:id", controller.GetPublicProfileHandler)
+		userGroup.GET("/:id/reviews", controller.GetUserReviewsHandler)
+		userGroup.POST("/:id/follow", controller.FollowSellerHandler)
+		userGroup.DELETE("/:id/follow", controller.UnfollowSellerHandler)
+	}
+
+	savedSearchGroup := router.Group("/api/saved-searches")
+	savedSearchGroup.Use(middlewares.AuthMiddleware())
+	{
+		savedSearchGroup.GET("", controller.GetSavedSearchesHandler)
+		savedSearchGroup.POST("", controller.CreateSavedSearchHandler)
+		savedSearchGroup.PUT("/:id", controller.UpdateSavedSearchHandler)
+		savedSearchGroup.DELETE("/:id", controller.DeleteSavedSearchHandler)
+	}
+
+	notificationGroup := router.Group("/api/notifications")
+	notificationGroup.Use(middlewares.AuthMiddleware())
+	{
+		notificationGroup.GET("", controller.GetNotificationsHandler)
+		notificationGroup.POST("/read-all", controller.MarkAllNotificationsReadHandler)
+		notificationGroup.POST("/:id/read", controller.MarkNotificationReadHandler)
+	}
+
+	paymentGroup := router.Group("/api/payments")
+	paymentGroup.Use(middlewares.AuthMiddleware())
+	{
+		paymentGroup.GET("", controller.GetPaymentsHandler)
+		paymentGroup.GET("/:id", controller.GetPaymentHandler)
+		paymentGroup.POST("/:id/method", controller.ChoosePaymentMethodHandler)
+		paymentGroup.POST("/:id/confirm", controller.ConfirmPaymentHandler)
+	}
+
+	deliveryGroup := router.Group("/api/deliveries")
+	deliveryGroup.Use(middlewares.AuthMiddleware())
+	{
+		deliveryGroup.GET("", controller.GetDeliveriesHandler)
+		deliveryGroup.GET("/:id", controller.GetDeliveryHandler)
+		deliveryGroup.POST("/:id/ship", controller.ShipDeliveryHandler)
+		deliveryGroup.POST("/:id/confirm", controller.ConfirmDeliveryHandler)
+		deliveryGroup.POST("/:id/dispute", controller.DisputeDeliveryHandler)
+		deliveryGroup.POST("/:id/accept-dispute", controller.AcceptDisputeHandler)
 	}
 
 	reviewGroup := router.Group("/api/reviews")
 	reviewGroup.Use(middlewares.AuthMiddleware())
 	{
 		reviewGroup.POST("", controller.SubmitReviewHandler)
+		reviewGroup.POST("/:id/reply", controller.ReplyToReviewHandler)
 	}
 }
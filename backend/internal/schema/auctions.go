@@ -3,34 +3,148 @@ package schema
 import "time"
 
 type ItemAuctionRequest struct {
-	Title       string    `json:"title" binding:"required"`
-	Description string    `json:"description" binding:"required"`
-	StartingBid float64   `json:"starting_bid" binding:"required"`
-	ImagePath   string    `json:"image_path" binding:"required"`
+	Title          string             `json:"title" binding:"required"`
+	Description    string             `json:"description" binding:"required"`
+	StartingBid    float64            `json:"starting_bid" binding:"required"`
+	Quantity       int                `json:"quantity"`
+	ImagePath      string             `json:"image_path" binding:"required"`
+	StartTime      time.Time          `json:"start_time" binding:"required"`
+	EndTime        time.Time          `json:"end_time" binding:"required"`
+	AuctionType    string             `json:"auction_type"`
+	ReservePrice   float64            `json:"reserve_price"`
+	BuyNowPrice    float64            `json:"buy_now_price"`
+	SoftClose      SoftCloseSettings  `json:"soft_close"`
+	Dutch          DutchSettings      `json:"dutch"`
+	BidIncrement   float64            `json:"bid_increment"`
+	IncrementTiers []BidIncrementTier `json:"increment_tiers"`
+	LotItems       []LotItem          `json:"lot_items"`
+	AutoRelist     int                `json:"auto_relist"`
+	Draft          bool               `json:"draft"`
+	CategoryID     int                `json:"category_id"`
+	Tags           []string           `json:"tags"`
+}
+
+// AuctionFilter narrows the auction list. Zero values match everything.
+type AuctionFilter struct {
+	CategoryID int
+	Tag        string
+}
+
+// AuctionUpdateRequest edits an auction before anyone has bid. Omitted fields are left unchanged.
+type AuctionUpdateRequest struct {
+	Title       *string    `json:"title"`
+	Description *string    `json:"description"`
+	ImagePath   *string    `json:"image_path"`
+	StartingBid *float64   `json:"starting_bid"`
+	StartTime   *time.Time `json:"start_time"`
+	EndTime     *time.Time `json:"end_time"`
+}
+
+// RelistRequest starts a new auction for an unsold item. A StartingBid of 0
+// keeps the previous starting bid.
+type RelistRequest struct {
 	StartTime   time.Time `json:"start_time" binding:"required"`
 	EndTime     time.Time `json:"end_time" binding:"required"`
+	StartingBid float64   `json:"starting_bid"`
+}
+
+// LotItem is one piece of a lot, described separately from the lot itself
+type LotItem struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	ImagePaths  []string `json:"image_paths"`
+}
+
+type SoftCloseSettings struct {
+	WindowMinutes    int `json:"window_minutes"`
+	ExtensionMinutes int `json:"extension_minutes"`
+	MaxExtensions    int `json:"max_extensions"`
+}
+
+// DutchSettings is the price schedule of a Dutch auction. The price opens at the
+// starting bid and drops by PriceStep every StepMinutes until it reaches FloorPrice.
+type DutchSettings struct {
+	FloorPrice   float64 `json:"floor_price"`
+	PriceStep    float64 `json:"price_step"`
+	StepMinutes  int     `json:"step_minutes"`
+	CurrentPrice float64 `json:"current_price,omitempty"`
+}
+
+// SellerReputation summarises the reviews and sales of a seller.
+// PositivePercent covers the last 12 months and is nil without recent reviews.
+type SellerReputation struct {
+	AverageRating   float64  `json:"average_rating"`
+	ReviewCount     int      `json:"review_count"`
+	PositivePercent *float64 `json:"positive_percent_12m"`
+	CompletedSales  int      `json:"completed_sales"`
+}
+
+type BidIncrementTier struct {
+	MinPrice  float64 `json:"min_price"`
+	Increment float64 `json:"increment"`
 }
 
 type AuctionResponse struct {
-    AuctionID           int       `json:"auction_id"`
-    ItemID              int       `json:"item_id"`
-    Title               string    `json:"title"`
-    Description         string    `json:"description"`
-    StartingBid         float64   `json:"starting_bid"`
-    CurrentHighestBid   float64   `json:"current_highest_bid"`
-    SellerID            int       `json:"seller_id"`
-    SellerName          string    `json:"seller_name"`
-    StartTime           time.Time `json:"start_time"`
-    EndTime             time.Time `json:"end_time"`
-    Status              string    `json:"status"`
-    ImagePath           string    `json:"image_path"`
-    CurrentUserBid      float64   `json:"current_user_bid"`
-    CurrentAutomatedBid float64   `json:"current_automated_bid"`
-    IsHighestBidder     bool      `json:"is_highest_bidder"`
+	AuctionID           int               `json:"auction_id"`
+	ItemID              int               `json:"item_id"`
+	Title               string            `json:"title"`
+	Description         string            `json:"description"`
+	StartingBid         float64           `json:"starting_bid"`
+	Quantity            int               `json:"quantity"`
+	CurrentHighestBid   float64           `json:"current_highest_bid"`
+	SellerID            int               `json:"seller_id"`
+	SellerName          string            `json:"seller_name"`
+	SellerReputation    SellerReputation  `json:"seller_reputation"`
+	StartTime           time.Time         `json:"start_time"`
+	EndTime             time.Time         `json:"end_time"`
+	Status              string            `json:"status"`
+	AuctionType         string            `json:"auction_type"`
+	ImagePath           string            `json:"image_path"`
+	CurrentUserBid      float64           `json:"current_user_bid"`
+	CurrentAutomatedBid float64           `json:"current_automated_bid"`
+	IsHighestBidder     bool              `json:"is_highest_bidder"`
+	NextMinBid          float64           `json:"next_min_bid"`
+	ReservePrice        float64           `json:"reserve_price,omitempty"`
+	HasReserve          bool              `json:"has_reserve"`
+	ReserveMet          bool              `json:"reserve_met"`
+	CloseReason         string            `json:"close_reason,omitempty"`
+	BuyNowPrice         float64           `json:"buy_now_price,omitempty"`
+	BuyNowAvailable     bool              `json:"buy_now_available"`
+	SoftClose           SoftCloseSettings `json:"soft_close"`
+	ExtensionCount      int               `json:"extension_count"`
+	Dutch               DutchSettings     `json:"dutch"`
+	LotSize             int               `json:"lot_size"`
+	LotItems            []LotItem         `json:"lot_items,omitempty"`
+	AutoRelist          int               `json:"auto_relist_remaining"`
+	RelistedFrom        int               `json:"relisted_from,omitempty"`
+	CategoryID          int               `json:"category_id,omitempty"`
+	Tags                []string          `json:"tags,omitempty"`
+	Watching            bool              `json:"watching"`
+	Watchers            int               `json:"watcher_count"`
+}
+
+// AuctionSearch is a full-text auction search. Every word of Query must match
+// the title, description or seller name, either whole or as a prefix. Zero
+// values of the other fields match everything.
+type AuctionSearch struct {
+	Query        string
+	MinPrice     float64
+	MaxPrice     float64
+	EndingBefore time.Time
+}
+
+// AuctionSearchResult is an auction matched by a search. The highlights are
+// excerpts with the matched words wrapped in <mark> tags.
+type AuctionSearchResult struct {
+	AuctionResponse
+	Rank                 float64 `json:"rank"`
+	TitleHighlight       string  `json:"title_highlight"`
+	DescriptionHighlight string  `json:"description_highlight"`
 }
 
 type BidCreate struct {
-	Amount float64 `json:"bid_amount" binding:"required"`
+	Amount   float64 `json:"bid_amount" binding:"required"`
+	Quantity int     `json:"quantity"`
 }
 
 type AutomatedBidCreate struct {
@@ -38,11 +152,15 @@ type AutomatedBidCreate struct {
 }
 
 type BidResponse struct {
-	BidID     int       `json:"bid_id"`
-	BuyerID   int       `json:"buyer_id"`
-	BuyerName string    `json:"buyer_name"`
-	Amount    float64   `json:"amount"`
-	BidTime   time.Time `json:"bid_time"`
-	AuctionID int       `json:"auction_id"`
-	ItemTitle string    `json:"item_title"`
+	BidID       int       `json:"bid_id"`
+	BuyerID     int       `json:"buyer_id"`
+	BuyerName   string    `json:"buyer_name"`
+	Amount      float64   `json:"amount"`
+	BidTime     time.Time `json:"bid_time"`
+	AuctionID   int       `json:"auction_id"`
+	ItemTitle   string    `json:"item_title"`
+	IsAutomated bool      `json:"is_automated"`
+	Quantity    int       `json:"quantity"`
+	Filled      int       `json:"filled_quantity"`
+	FillStatus  string    `json:"fill_status,omitempty"`
 }
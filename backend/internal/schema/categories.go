@@ -0,0 +1,16 @@
+package schema
+
+type CategoryRequest struct {
+	Name     string `json:"name" binding:"required"`
+	ParentID *int   `json:"parent_id"`
+}
+
+// CategoryResponse is a category with its subcategories. OpenAuctions counts
+// open auctions in the whole subtree.
+type CategoryResponse struct {
+	CategoryID   int                `json:"category_id"`
+	ParentID     *int               `json:"parent_id"`
+	Name         string             `json:"name"`
+	OpenAuctions int                `json:"open_auctions"`
+	Children     []CategoryResponse `json:"children,omitempty"`
+}
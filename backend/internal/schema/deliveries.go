@@ -0,0 +1,39 @@
+package schema
+
+import "time"
+
+type DeliveryResponse struct {
+	DeliveryID     int        `json:"delivery_id"`
+	TransactionID  int        `json:"transaction_id"`
+	AuctionID      int        `json:"auction_id"`
+	Title          string     `json:"title"`
+	BuyerID        int        `json:"buyer_id"`
+	BuyerName      string     `json:"buyer_name"`
+	SellerID       int        `json:"seller_id"`
+	SellerName     string     `json:"seller_name"`
+	Status         string     `json:"delivery_status"`
+	Carrier        *string    `json:"carrier,omitempty"`
+	TrackingNumber *string    `json:"tracking_number,omitempty"`
+	ShippedAt      *time.Time `json:"shipped_at,omitempty"`
+	DeliveredAt    *time.Time `json:"delivery_date,omitempty"`
+	AutoConfirmed  bool       `json:"auto_confirmed"`
+	DisputedAt     *time.Time `json:"disputed_at,omitempty"`
+	DisputeReason  *string    `json:"dispute_reason,omitempty"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+type ShipmentRequest struct {
+	Carrier        string `json:"carrier" binding:"required"`
+	TrackingNumber string `json:"tracking_number" binding:"required"`
+}
+
+type DisputeRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// DisputeResolutionRequest is an admin's ruling on a dispute: "refund" to
+// refund the buyer or "release" to treat the item as delivered
+type DisputeResolutionRequest struct {
+	Outcome string `json:"outcome" binding:"required"`
+}
@@ -0,0 +1,38 @@
+package schema
+
+import "time"
+
+// BalanceResponse is a seller's money in the escrow ledger. Held is paid by
+// buyers but waiting on delivery; Available has been released or paid out to
+// the seller.
+type BalanceResponse struct {
+	Available float64 `json:"available"`
+	Held      float64 `json:"held"`
+}
+
+type LedgerEntryResponse struct {
+	EntryID       int       `json:"entry_id"`
+	TransactionID int       `json:"transaction_id"`
+	AuctionID     int       `json:"auction_id"`
+	Title         string    `json:"title"`
+	Type          string    `json:"entry_type"`
+	FromAccount   string    `json:"from_account"`
+	ToAccount     string    `json:"to_account"`
+	Amount        float64   `json:"amount"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type LedgerAccountResponse struct {
+	AccountID int     `json:"account_id"`
+	Type      string  `json:"account_type"`
+	UserID    *int    `json:"user_id,omitempty"`
+	Username  *string `json:"username,omitempty"`
+	Balance   float64 `json:"balance"`
+}
+
+// LedgerAuditResponse lists every ledger account. Total is the sum of all
+// balances, which double entry keeps at zero.
+type LedgerAuditResponse struct {
+	Accounts []LedgerAccountResponse `json:"accounts"`
+	Total    float64                 `json:"total"`
+}
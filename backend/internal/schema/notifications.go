@@ -0,0 +1,12 @@
+package schema
+
+import "time"
+
+type NotificationResponse struct {
+	NotificationID int       `json:"notification_id"`
+	Type           string    `json:"type"`
+	AuctionID      int       `json:"auction_id,omitempty"`
+	Message        string    `json:"message"`
+	Read           bool      `json:"read"`
+	CreatedAt      time.Time `json:"created_at"`
+}
@@ -0,0 +1,20 @@
+package schema
+
+import "time"
+
+type SecondChanceOfferRequest struct {
+	ExpiresInHours int `json:"expires_in_hours"`
+}
+
+type SecondChanceOfferResponse struct {
+	OfferID     int        `json:"offer_id"`
+	AuctionID   int        `json:"auction_id"`
+	Title       string     `json:"title"`
+	BuyerID     int        `json:"buyer_id"`
+	BuyerName   string     `json:"buyer_name"`
+	Amount      float64    `json:"amount"`
+	Status      string     `json:"status"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	RespondedAt *time.Time `json:"responded_at,omitempty"`
+}
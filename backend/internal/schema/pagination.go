@@ -0,0 +1,20 @@
+package schema
+
+// PageRequest selects one page of a list. Cursor is the NextCursor of the
+// previous page, or empty for the first page. Sort and Status take the values
+// listed by each endpoint; empty values use the endpoint's defaults.
+type PageRequest struct {
+	Cursor string
+	Limit  int
+	Sort   string
+	Status string
+}
+
+// Page is one page of a list. NextCursor is empty on the last page.
+// TotalEstimate counts every matching row, but stops counting at a cap so
+// that large lists stay cheap.
+type Page[T any] struct {
+	Items         []T    `json:"items"`
+	NextCursor    string `json:"next_cursor,omitempty"`
+	TotalEstimate int    `json:"total_estimate"`
+}
@@ -0,0 +1,22 @@
+package schema
+
+import "time"
+
+type PaymentResponse struct {
+	PaymentID         int        `json:"payment_id"`
+	TransactionID     int        `json:"transaction_id"`
+	AuctionID         int        `json:"auction_id"`
+	Title             string     `json:"title"`
+	Amount            float64    `json:"amount"`
+	Method            *string    `json:"payment_method"`
+	ProviderReference *string    `json:"provider_reference,omitempty"`
+	Status            string     `json:"payment_status"`
+	Escrowed          bool       `json:"escrowed"`
+	CreatedAt         time.Time  `json:"created_at"`
+	DueAt             time.Time  `json:"due_at"`
+	PaidAt            *time.Time `json:"payment_date,omitempty"`
+}
+
+type PaymentMethodRequest struct {
+	Method string `json:"payment_method" binding:"required"`
+}
@@ -17,3 +17,27 @@ type ProfileResponse struct {
 	MobileNumber string    `json:"mobile_number"`
 	CreatedAt    time.Time `json:"created_at"`
 }
+
+// PublicProfileResponse is what anyone can see of a user. It leaves out the
+// contact details in ProfileResponse.
+type PublicProfileResponse struct {
+	UserID         int                   `json:"user_id"`
+	Username       string                `json:"username"`
+	CreatedAt      time.Time             `json:"created_at"`
+	Reputation     SellerReputation      `json:"reputation"`
+	UnpaidStrikes  int                   `json:"unpaid_strikes"`
+	FollowerCount  int                   `json:"follower_count"`
+	Following      bool                  `json:"following"`
+	ActiveListings Page[AuctionResponse] `json:"active_listings"`
+}
+
+type FollowRequest struct {
+	Delivery string `json:"alert_delivery"`
+}
+
+type FollowedSellerResponse struct {
+	SellerID   int       `json:"seller_id"`
+	SellerName string    `json:"seller_name"`
+	Delivery   string    `json:"alert_delivery"`
+	FollowedAt time.Time `json:"followed_at"`
+}
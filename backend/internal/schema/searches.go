@@ -0,0 +1,21 @@
+package schema
+
+import "time"
+
+type SavedSearchRequest struct {
+	Name       string  `json:"name" binding:"required"`
+	Keywords   string  `json:"keywords"`
+	CategoryID *int    `json:"category_id"`
+	MaxPrice   float64 `json:"max_price"`
+	Delivery   string  `json:"alert_delivery"`
+}
+
+type SavedSearchResponse struct {
+	SearchID   int       `json:"search_id"`
+	Name       string    `json:"name"`
+	Keywords   string    `json:"keywords"`
+	CategoryID *int      `json:"category_id,omitempty"`
+	MaxPrice   float64   `json:"max_price,omitempty"`
+	Delivery   string    `json:"alert_delivery"`
+	CreatedAt  time.Time `json:"created_at"`
+}
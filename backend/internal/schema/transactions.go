@@ -6,12 +6,40 @@ type TransactionResponse struct {
 	TransactionID int       `json:"transaction_id"`
 	AuctionID     int       `json:"auction_id"`
 	Title         string    `json:"title"`
+	Quantity      int       `json:"quantity"`
 	Price         float64   `json:"price"`
 	Date          time.Time `json:"purchase_date"`
 	Review        int       `json:"review"`
+	LotItems      []LotItem `json:"lot_items,omitempty"`
 }
 
+// ReviewRequest reviews the other party of an auction's transaction. The seller
+// of a multi-quantity auction names the buyer they are reviewing.
 type ReviewRequest struct {
-	AuctionID int `json:"auction_id" binding:"required"`
-	Rating    int `json:"rating" binding:"required"`
+	AuctionID int    `json:"auction_id" binding:"required"`
+	BuyerID   int    `json:"buyer_id"`
+	Rating    int    `json:"rating" binding:"required"`
+	Comment   string `json:"comment" binding:"required"`
+}
+
+type ReviewReplyRequest struct {
+	Reply string `json:"reply" binding:"required"`
+}
+
+// ReviewResponse is a public review. ReviewerRole is whether the reviewer was
+// the buyer or the seller in the transaction.
+type ReviewResponse struct {
+	ReviewID     int        `json:"review_id"`
+	AuctionID    int        `json:"auction_id"`
+	Title        string     `json:"title"`
+	ReviewerID   int        `json:"reviewer_id"`
+	ReviewerName string     `json:"reviewer_name"`
+	RevieweeID   int        `json:"reviewee_id"`
+	ReviewerRole string     `json:"reviewer_role"`
+	Rating       int        `json:"rating"`
+	Comment      string     `json:"comment"`
+	Date         time.Time  `json:"review_date"`
+	UpdatedAt    *time.Time `json:"updated_at,omitempty"`
+	Reply        *string    `json:"reply,omitempty"`
+	ReplyDate    *time.Time `json:"reply_date,omitempty"`
 }
@@ -4,14 +4,17 @@ import (
 	"encoding/json"
 	"log"
 	"sync"
+	"time"
 
 	"github.com/gorilla/websocket"
 )
 
 const (
-	EventNewBid        = "new_bid"
-	EventNewAuction    = "new_auction"
-	EventAuctionStatus = "auction_status"
+	EventNewBid          = "new_bid"
+	EventNewAuction      = "new_auction"
+	EventAuctionStatus   = "auction_status"
+	EventAuctionExtended = "auction_extended"
+	EventPriceDrop       = "price_drop"
 )
 
 type Manager struct {
@@ -98,13 +101,15 @@ func (m *Manager) BroadcastNewAuction(auctionDetails interface{}) {
 	log.Println("Broadcasting new auction")
 }
 
-// BroadcastAuctionStatus broadcasts auction status changes to all connected clients
-func (m *Manager) BroadcastAuctionStatus(auctionID int, status string, auctionDetails interface{}) {
+// BroadcastAuctionStatus broadcasts auction status changes to all connected clients.
+// reason explains a closing (e.g. "sold") and is empty otherwise.
+func (m *Manager) BroadcastAuctionStatus(auctionID int, status string, reason string, auctionDetails interface{}) {
 	data := map[string]interface{}{
 		"type": EventAuctionStatus,
 		"data": map[string]interface{}{
 			"auction_id": auctionID,
 			"status":     status,
+			"reason":     reason,
 			"auction":    auctionDetails,
 		},
 	}
@@ -118,3 +123,43 @@ func (m *Manager) BroadcastAuctionStatus(auctionID int, status string, auctionDe
 	m.broadcast <- jsonData
 	log.Printf("Broadcasting auction #%d status change to: %s", auctionID, status)
 }
+
+// BroadcastAuctionExtended broadcasts a soft-close extension so clients can update their countdowns
+func (m *Manager) BroadcastAuctionExtended(auctionID int, endTime time.Time) {
+	data := map[string]interface{}{
+		"type": EventAuctionExtended,
+		"data": map[string]interface{}{
+			"auction_id": auctionID,
+			"end_time":   endTime,
+		},
+	}
+
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Error marshalling auction extended event: %v", err)
+		return
+	}
+
+	m.broadcast <- jsonData
+	log.Printf("Broadcasting auction #%d extended to %s", auctionID, endTime.Format("2006-01-02 15:04:05"))
+}
+
+// BroadcastPriceDrop broadcasts the new price of a Dutch auction after a scheduled step
+func (m *Manager) BroadcastPriceDrop(auctionID int, price float64) {
+	data := map[string]interface{}{
+		"type": EventPriceDrop,
+		"data": map[string]interface{}{
+			"auction_id":    auctionID,
+			"current_price": price,
+		},
+	}
+
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Error marshalling price drop event: %v", err)
+		return
+	}
+
+	m.broadcast <- jsonData
+	log.Printf("Broadcasting auction #%d price drop to %.2f", auctionID, price)
+}
@@ -9,6 +9,7 @@ import (
 	"Online-Auction-System/backend/internal/controller"
 	"Online-Auction-System/backend/internal/websockets"
 	"Online-Auction-System/backend/internal/cronjob"
+	"Online-Auction-System/backend/internal/payments"
 )
 
 var wsManager *websockets.Manager
@@ -19,6 +20,7 @@ func init() {
 	wsManager = websockets.NewManager()
 	go wsManager.Run()
 	controller.SetWebSocketManager(wsManager)
+	controller.SetPaymentProvider(payments.NewLocalProvider())
 	cronjob.StartAuctionEndCron()
 }
 
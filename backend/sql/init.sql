@@ -1,16 +1,30 @@
 -- Drop tables and types in the correct order with CASCADE to handle dependencies
 DROP TABLE IF EXISTS users CASCADE;
+DROP TABLE IF EXISTS categories CASCADE;
 DROP TABLE IF EXISTS items CASCADE;
+DROP TABLE IF EXISTS item_tags CASCADE;
+DROP TABLE IF EXISTS lot_items CASCADE;
 DROP TABLE IF EXISTS auctions CASCADE;
 DROP TABLE IF EXISTS bids CASCADE;
+DROP TABLE IF EXISTS auction_bid_increments CASCADE;
 DROP TABLE IF EXISTS automated_bids CASCADE;
 DROP TABLE IF EXISTS auction_participants CASCADE;
+DROP TABLE IF EXISTS watchlist CASCADE;
+DROP TABLE IF EXISTS saved_searches CASCADE;
+DROP TABLE IF EXISTS saved_search_matches CASCADE;
+DROP TABLE IF EXISTS notifications CASCADE;
+DROP TABLE IF EXISTS seller_follows CASCADE;
 DROP TABLE IF EXISTS transactions CASCADE;
+DROP TABLE IF EXISTS second_chance_offers CASCADE;
 DROP TABLE IF EXISTS deliveries CASCADE;
 DROP TABLE IF EXISTS payments CASCADE;
 DROP TABLE IF EXISTS admin_delete_log CASCADE;
 DROP TABLE IF EXISTS admin_update_log CASCADE;
 DROP TABLE IF EXISTS reviews CASCADE;
+DROP TABLE IF EXISTS seller_reputation CASCADE;
+DROP TABLE IF EXISTS buyer_strikes CASCADE;
+DROP TABLE IF EXISTS ledger_entries CASCADE;
+DROP TABLE IF EXISTS ledger_accounts CASCADE;
 
 -- Stores user login and contact information (each user has one address and one mobile number). The backend ensures that if another person tries to login with a number or address or email or username already in use, that is prevented
 CREATE TABLE users (
@@ -21,10 +35,21 @@ CREATE TABLE users (
     address VARCHAR(255) NOT NULL,       -- single address per user
     mobile_number CHAR(10) NOT NULL,    -- single mobile number per user
     is_admin BOOLEAN DEFAULT FALSE,
+    digest_sent_at TIMESTAMP,           -- when the last saved-search digest email went out
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
 
+--Hierarchical item categories managed by admins. Top-level categories have no parent.
+CREATE TABLE categories (
+    category_id SERIAL PRIMARY KEY,
+    parent_id INTEGER REFERENCES categories(category_id),
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (parent_id, name)
+);
+
+
 --Each item is created by a seller (a user) and may later be auctioned, so current_highest_bid and bidder may be NULL
 CREATE TABLE items (
     item_id SERIAL PRIMARY KEY,
@@ -33,38 +58,92 @@ CREATE TABLE items (
     description TEXT,
     image_path VARCHAR(255) NOT NULL,
     starting_bid DECIMAL(10,2) NOT NULL,
+    quantity INTEGER NOT NULL DEFAULT 1 CHECK (quantity > 0),   -- identical units offered in one listing
+    reserve_price DECIMAL(10,2),         -- hidden minimum sale price; NULL when the seller sets none
+    buy_now_price DECIMAL(10,2),         -- price that ends the auction immediately; NULL when not offered
     current_highest_bid DECIMAL(10,2),
     current_highest_bidder INTEGER REFERENCES users(user_id),
-    highest_automated_bid DECIMAL(10,2),
+    category_id INTEGER REFERENCES categories(category_id),
+    search_vector TSVECTOR,              -- title, description and seller name for full-text search; kept current by triggers
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
+--Free-form tags chosen by the seller, stored lowercase
+CREATE TABLE item_tags (
+    item_id INTEGER NOT NULL REFERENCES items(item_id),
+    tag VARCHAR(50) NOT NULL,
+    PRIMARY KEY (item_id, tag)
+);
+
+
+--The pieces bundled into a lot. A lot is an item with lot_items rows; its auction sells every piece together to one winner.
+CREATE TABLE lot_items (
+    lot_item_id SERIAL PRIMARY KEY,
+    item_id INTEGER NOT NULL REFERENCES items(item_id),
+    position INTEGER NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    image_paths TEXT[] NOT NULL DEFAULT '{}',
+    UNIQUE (item_id, position)
+);
+
 
---An auction is created for an item. (One auction per item.)
+--An auction is created for an item. An unsold item can be relisted, so an item may have several auctions over time; relisted_from links a relisting to the
+--auction it replaces, whose bids are kept. close_reason records how a closed auction ended. auto_relist_remaining is how many more times a no-bid auction is relisted automatically.
+--The item's current_highest_bid/bidder belong to its latest auction, so relisting freezes the old auction's result into final_price/final_bidder and sets relisted_at.
+--Dutch auctions open at the item's starting_bid and drop by dutch_price_step every dutch_step_minutes until dutch_floor_price; dutch_current_price is the price on offer.
 CREATE TABLE auctions (
     auction_id SERIAL PRIMARY KEY,
-    item_id INTEGER NOT NULL UNIQUE REFERENCES items(item_id),
+    item_id INTEGER NOT NULL REFERENCES items(item_id),
     start_time TIMESTAMP NOT NULL,
     end_time TIMESTAMP NOT NULL,
-    auction_status VARCHAR(20) CHECK (auction_status IN ('open', 'closed', 'deleted')) NOT NULL DEFAULT 'open'
+    auction_status VARCHAR(20) CHECK (auction_status IN ('draft', 'scheduled', 'open', 'closed', 'deleted')) NOT NULL DEFAULT 'open', -- drafts stay private until published; scheduled auctions open at start_time
+    auction_type VARCHAR(30) CHECK (auction_type IN ('english', 'sealed_first_price', 'sealed_second_price', 'dutch')) NOT NULL DEFAULT 'english',
+    close_reason VARCHAR(30) CHECK (close_reason IN ('sold', 'no_bids', 'reserve_not_met')),
+    soft_close_window INTEGER CHECK (soft_close_window > 0),             -- minutes before end_time in which a bid extends the auction; NULL disables soft close
+    soft_close_extension INTEGER CHECK (soft_close_extension > 0),       -- minutes added to end_time per extension
+    soft_close_max_extensions INTEGER CHECK (soft_close_max_extensions > 0), -- NULL means no cap
+    extension_count INTEGER NOT NULL DEFAULT 0,
+    dutch_floor_price DECIMAL(10,2) CHECK (dutch_floor_price > 0),
+    dutch_price_step DECIMAL(10,2) CHECK (dutch_price_step > 0),
+    dutch_step_minutes INTEGER CHECK (dutch_step_minutes > 0),
+    dutch_current_price DECIMAL(10,2),
+    auto_relist_remaining INTEGER NOT NULL DEFAULT 0 CHECK (auto_relist_remaining >= 0),
+    relisted_from INTEGER REFERENCES auctions(auction_id),
+    relisted_at TIMESTAMP,
+    final_price DECIMAL(10,2),
+    final_bidder INTEGER REFERENCES users(user_id)
 );
 
 
---Records bids made by buyers on auctions.
+--Records bids made by buyers on auctions. is_automated marks bids placed by the proxy engine on a bidder's behalf.
+--In multi-quantity auctions bid_amount is the price per unit and each bidder keeps a single bid that they revise.
 CREATE TABLE bids (
     bid_id SERIAL PRIMARY KEY,
     auction_id INTEGER NOT NULL REFERENCES auctions(auction_id),
     buyer_id INTEGER NOT NULL REFERENCES users(user_id),
     bid_amount DECIMAL(10,2) NOT NULL CHECK (bid_amount > 0),
-    bid_time TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+    quantity INTEGER NOT NULL DEFAULT 1 CHECK (quantity > 0),
+    bid_time TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    is_automated BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+--Increment schedule chosen by the seller. A bid at or above min_price must raise the price by at least increment. Auctions without rows use the site-wide default.
+CREATE TABLE auction_bid_increments (
+    auction_id INTEGER NOT NULL REFERENCES auctions(auction_id),
+    min_price DECIMAL(10,2) NOT NULL CHECK (min_price >= 0),
+    increment DECIMAL(10,2) NOT NULL CHECK (increment > 0),
+    PRIMARY KEY (auction_id, min_price)
 );
 
+--Each bidder's maximum (proxy) bid on an auction. The proxy engine bids on their behalf up to this ceiling.
 CREATE TABLE automated_bids (
     bid_id SERIAL PRIMARY KEY,
     auction_id INTEGER NOT NULL REFERENCES auctions(auction_id),
     buyer_id INTEGER NOT NULL REFERENCES users(user_id),
     bid_amount DECIMAL(10,2) NOT NULL CHECK (bid_amount > 0),
-    bid_time TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    bid_time TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (auction_id, buyer_id)
 );
 
 --Keeps track of which users participated in a given auction and in what role (buyer or seller). A user might appear as a seller in one auction and as a buyer in another.
@@ -77,35 +156,163 @@ CREATE TABLE auction_participants (
 );
 
 
---Captures completed sales (to maintain buy-history and sell-history).
+--Auctions a user has starred without bidding. reminder_sent_at is set once the ending-soon email has gone out.
+CREATE TABLE watchlist (
+    user_id INTEGER NOT NULL REFERENCES users(user_id),
+    auction_id INTEGER NOT NULL REFERENCES auctions(auction_id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    reminder_sent_at TIMESTAMP,
+    PRIMARY KEY (user_id, auction_id)
+);
+
+
+--Searches a user saved to hear about new listings. ts_query is keywords converted to tsquery syntax; empty criteria match everything.
+CREATE TABLE saved_searches (
+    search_id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(user_id),
+    name VARCHAR(100) NOT NULL,
+    keywords VARCHAR(255) NOT NULL DEFAULT '',
+    ts_query TEXT NOT NULL DEFAULT '',
+    category_id INTEGER REFERENCES categories(category_id),
+    max_price DECIMAL(10,2),
+    alert_delivery VARCHAR(20) NOT NULL DEFAULT 'in_app' CHECK (alert_delivery IN ('in_app', 'daily_digest')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+--Auctions that matched a saved search when they opened. notified_at is set once the user was told, in-app or by digest.
+CREATE TABLE saved_search_matches (
+    search_id INTEGER NOT NULL REFERENCES saved_searches(search_id) ON DELETE CASCADE,
+    auction_id INTEGER NOT NULL REFERENCES auctions(auction_id),
+    matched_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    notified_at TIMESTAMP,
+    PRIMARY KEY (search_id, auction_id)
+);
+
+--Sellers a user follows to hear about their new listings, by email or in-app notification
+CREATE TABLE seller_follows (
+    follower_id INTEGER NOT NULL REFERENCES users(user_id),
+    seller_id INTEGER NOT NULL REFERENCES users(user_id),
+    alert_delivery VARCHAR(20) NOT NULL DEFAULT 'in_app' CHECK (alert_delivery IN ('in_app', 'email')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (follower_id, seller_id),
+    CHECK (follower_id != seller_id)
+);
+
+--In-app notifications shown to a user until read
+CREATE TABLE notifications (
+    notification_id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(user_id),
+    notification_type VARCHAR(30) NOT NULL,
+    auction_id INTEGER REFERENCES auctions(auction_id),
+    message TEXT NOT NULL,
+    is_read BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+
+--Captures completed sales (to maintain buy-history and sell-history). amount is the total the buyer pays, which for second-price auctions is below their bid.
+--A multi-quantity auction creates one transaction per winning bidder, each paying the uniform clearing price for quantity units.
 CREATE TABLE transactions (
     transaction_id SERIAL PRIMARY KEY,
     auction_id INTEGER NOT NULL REFERENCES auctions(auction_id),
+    buyer_id INTEGER NOT NULL REFERENCES users(user_id),
+    quantity INTEGER NOT NULL DEFAULT 1 CHECK (quantity > 0),
+    amount DECIMAL(10,2) NOT NULL CHECK (amount > 0),
     transaction_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
 
+--Offers of an unsold item to a runner-up bidder at their own highest bid, made when the reserve was missed or the winner did not pay. A pending offer lapses at expires_at.
+CREATE TABLE second_chance_offers (
+    offer_id SERIAL PRIMARY KEY,
+    auction_id INTEGER NOT NULL REFERENCES auctions(auction_id),
+    buyer_id INTEGER NOT NULL REFERENCES users(user_id),
+    amount DECIMAL(10,2) NOT NULL CHECK (amount > 0),
+    offer_status VARCHAR(20) CHECK (offer_status IN ('pending', 'accepted', 'declined', 'expired')) NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    responded_at TIMESTAMP,
+    UNIQUE (auction_id, buyer_id)
+);
+
+
 --Tracks the shipment/delivery status for a transaction. Failed is used to indicate the case when payment is not made in stipulated time. The seller and buyer, both must be notified of this, and the auction, bid and items tables must be updated through transactions by deleting that auction and bid's record, and enabling the seller to host another auction for this item
+--A delivery is created as pending once the payment completes. The seller marks it shipped with the carrier and tracking number, and the buyer confirms receipt
+--or raises a dispute. Shipments not confirmed or disputed within DELIVERY_CONFIRM_DAYS are confirmed automatically. A dispute is resolved, at resolved_at, by the
+--seller accepting it, which refunds the buyer, or by an admin either refunding the buyer or marking the item delivered.
 CREATE TABLE deliveries (
     delivery_id SERIAL PRIMARY KEY,
-    transaction_id INTEGER NOT NULL REFERENCES transactions(transaction_id),
-    delivery_status VARCHAR(20) CHECK (delivery_status IN ('pending', 'shipped', 'delivered', 'failed')) NOT NULL DEFAULT 'pending',
-    delivery_date TIMESTAMP
+    transaction_id INTEGER NOT NULL UNIQUE REFERENCES transactions(transaction_id),
+    delivery_status VARCHAR(20) CHECK (delivery_status IN ('pending', 'shipped', 'delivered', 'failed', 'refunded')) NOT NULL DEFAULT 'pending',
+    carrier VARCHAR(50),
+    tracking_number VARCHAR(100),
+    shipped_at TIMESTAMP,
+    delivery_date TIMESTAMP,
+    auto_confirmed BOOLEAN NOT NULL DEFAULT FALSE,
+    disputed_at TIMESTAMP,
+    dispute_reason TEXT,
+    resolved_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
 --Records payment details for transactions, and failed indicates the same as what was mentioned before. Changes need to be made in this case. Delivery and payment are not directly linked, but both are linked to transactions, which acts as an intermediate to these two
+--Each transaction gets one payment request when it is created, due by due_at. The buyer then picks a method, which opens a charge with the payment provider, and confirms it.
+--escrowed is set when the request is created for amounts of at least ESCROW_THRESHOLD: those are held in escrow until delivery, and smaller ones are paid out at once.
 CREATE TABLE payments (
     payment_id SERIAL PRIMARY KEY,
+    transaction_id INTEGER NOT NULL UNIQUE REFERENCES transactions(transaction_id),
+    amount DECIMAL(10,2) NOT NULL CHECK (amount > 0),
+    payment_method VARCHAR(30) CHECK (payment_method IN ('credit_card', 'UPI', 'bank_transfer')),    -- NULL until the buyer chooses one
+    provider VARCHAR(30),
+    provider_reference VARCHAR(100),
+    payment_date TIMESTAMP,    -- when the payment completed
+    payment_status VARCHAR(20) CHECK (payment_status IN ('pending', 'completed', 'failed', 'refunded')) NOT NULL DEFAULT 'pending',
+    escrowed BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    due_at TIMESTAMP NOT NULL
+);
+
+
+--Strikes against buyers who did not pay for an auction they won by the payment deadline. One per unpaid transaction.
+CREATE TABLE buyer_strikes (
+    strike_id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(user_id),
+    transaction_id INTEGER NOT NULL UNIQUE REFERENCES transactions(transaction_id),
+    reason VARCHAR(30) NOT NULL DEFAULT 'unpaid',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+
+--Accounts of the escrow ledger. Money paid through the payment provider comes out of the 'provider' account, is held in the 'escrow' account until the
+--delivery is confirmed, and is then released to the seller's 'seller' account. Payments below the escrow threshold go straight to the seller's account instead.
+--A refund sends the money back to the provider from whichever account holds it. There is one provider and one escrow account, and one seller account per seller.
+CREATE TABLE ledger_accounts (
+    account_id SERIAL PRIMARY KEY,
+    account_type VARCHAR(20) CHECK (account_type IN ('provider', 'escrow', 'seller')) NOT NULL,
+    user_id INTEGER REFERENCES users(user_id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((account_type = 'seller') = (user_id IS NOT NULL))
+);
+
+--Double-entry ledger: every entry debits from_account and credits to_account with the same amount, so the balances of all accounts always sum to zero.
+--An account's balance is what it was credited less what it was debited. Each transaction has at most one entry of each type.
+CREATE TABLE ledger_entries (
+    entry_id SERIAL PRIMARY KEY,
     transaction_id INTEGER NOT NULL REFERENCES transactions(transaction_id),
-    payment_method VARCHAR(30) CHECK (payment_method IN ('credit_card', 'UPI', 'bank_transfer')) NOT NULL,
-    payment_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    payment_status VARCHAR(20) CHECK (payment_status IN ('pending', 'completed', 'failed')) NOT NULL
+    entry_type VARCHAR(20) CHECK (entry_type IN ('escrow_hold', 'escrow_release', 'escrow_refund', 'payout', 'payout_refund')) NOT NULL,
+    from_account_id INTEGER NOT NULL REFERENCES ledger_accounts(account_id),
+    to_account_id INTEGER NOT NULL REFERENCES ledger_accounts(account_id),
+    amount DECIMAL(10,2) NOT NULL CHECK (amount > 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (transaction_id, entry_type),
+    CHECK (from_account_id <> to_account_id)
 );
 
 
 --Keeps an audit log of auction end_time changes made on key tables.
 CREATE TABLE admin_update_log (
     log_id SERIAL PRIMARY KEY,
+    auction_id INTEGER REFERENCES auctions(auction_id),
     old_time TIMESTAMP,
     new_time TIMESTAMP,
     changed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
@@ -120,12 +327,33 @@ CREATE TABLE admin_delete_log (
     changed_by SERIAL REFERENCES users(user_id)           -- e.g. admin username
 );
 
---Allows buyers (or sellers) to review their counterpart after a transaction, but honestly just to complete 10 tables. Again, it is not directly linked to any item, but linked to transactions table, which is linked to auctions, which is linked to item
+--Lets the buyer and the seller of a transaction review each other once, with a rating and a comment. The reviewed party may reply once, publicly.
+--Reviews can be edited for a limited time after they are first written (see REVIEW_EDIT_DAYS).
 CREATE TABLE reviews (
     review_id SERIAL PRIMARY KEY,
-    transaction_id INTEGER NOT NULL UNIQUE REFERENCES transactions(transaction_id),
+    transaction_id INTEGER NOT NULL REFERENCES transactions(transaction_id),
+    reviewer_id INTEGER NOT NULL REFERENCES users(user_id),
+    reviewee_id INTEGER NOT NULL REFERENCES users(user_id),
+    reviewer_role VARCHAR(10) NOT NULL CHECK (reviewer_role IN ('buyer', 'seller')),
     rating INTEGER CHECK (rating BETWEEN 1 AND 5) NOT NULL,
-    review_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    comment TEXT NOT NULL,
+    review_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,    -- when the review was first written; the edit window runs from here
+    updated_at TIMESTAMP,
+    reply TEXT,
+    reply_date TIMESTAMP,
+    UNIQUE (transaction_id, reviewer_id),
+    CHECK (reviewer_id <> reviewee_id)
+);
+
+--Cached seller reputation, refreshed by triggers when a sale, review or payment failure is recorded and daily by the scheduler so the 12-month window stays current.
+--Only buyers' reviews of the seller count, and a review of 4 or 5 counts as positive. Only sales whose payment has completed count as completed sales.
+CREATE TABLE seller_reputation (
+    seller_id INTEGER PRIMARY KEY REFERENCES users(user_id),
+    average_rating DECIMAL(3,2) NOT NULL DEFAULT 0,
+    review_count INTEGER NOT NULL DEFAULT 0,
+    positive_percent_12m DECIMAL(5,2),    -- NULL when there were no reviews in the last 12 months
+    completed_sales INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
 DROP FUNCTION IF EXISTS update_highest_bid() CASCADE;
@@ -135,6 +363,15 @@ DROP PROCEDURE IF EXISTS send_notification(p_auction_id integer, p_item_id integ
 DROP PROCEDURE IF EXISTS close_auction(p_auction_id integer);
 DROP FUNCTION IF EXISTS check_auction_end() CASCADE;
 DROP TRIGGER IF EXISTS trg_auction_end_check ON auctions;
+DROP FUNCTION IF EXISTS item_search_vector(text, text, text) CASCADE;
+DROP FUNCTION IF EXISTS update_item_search_vector() CASCADE;
+DROP FUNCTION IF EXISTS update_seller_search_vectors() CASCADE;
+DROP FUNCTION IF EXISTS refresh_seller_reputation(integer) CASCADE;
+DROP FUNCTION IF EXISTS update_seller_reputation() CASCADE;
+DROP FUNCTION IF EXISTS ledger_account(varchar, integer) CASCADE;
+DROP FUNCTION IF EXISTS hold_escrow() CASCADE;
+DROP FUNCTION IF EXISTS release_escrow() CASCADE;
+DROP FUNCTION IF EXISTS refund_payment() CASCADE;
 
 
 CREATE OR REPLACE FUNCTION update_highest_bid() 
@@ -142,6 +379,7 @@ RETURNS TRIGGER AS $$
 DECLARE
     v_item_id INTEGER;
     v_current_bid DECIMAL(10,2);
+    v_starting_bid DECIMAL(10,2);
     v_auction_status VARCHAR(20);
 BEGIN
     -- Retrieve the associated item_id from the auctions table while locking the row.
@@ -157,15 +395,15 @@ BEGIN
         RETURN NEW;
     END IF;
     
-    -- Retrieve the current highest bid (or starting bid if no bid exists) and the item status from the items table.
-    SELECT COALESCE(current_highest_bid, starting_bid)
-      INTO v_current_bid
+    -- Retrieve the current highest bid and the starting bid from the items table.
+    SELECT current_highest_bid, starting_bid
+      INTO v_current_bid, v_starting_bid
       FROM items 
      WHERE item_id = v_item_id
        FOR UPDATE;
     
-    -- If the new bid exceeds the current bid, update the highest bid and highest bidder.
-    IF NEW.bid_amount > v_current_bid THEN
+    -- If the new bid exceeds the current bid (or meets the starting bid for the first bid), update the highest bid and highest bidder.
+    IF (v_current_bid IS NULL AND NEW.bid_amount >= v_starting_bid) OR NEW.bid_amount > v_current_bid THEN
         UPDATE items
            SET current_highest_bid = NEW.bid_amount,
                current_highest_bidder = NEW.buyer_id
@@ -185,17 +423,215 @@ AFTER INSERT ON bids
 FOR EACH ROW
 EXECUTE FUNCTION update_highest_bid();
 
+-- Builds the search document of an item: the title ranks above the description, which ranks above the seller name.
+CREATE OR REPLACE FUNCTION item_search_vector(p_title text, p_description text, p_seller text)
+RETURNS tsvector AS $$
+    SELECT setweight(to_tsvector('english', COALESCE(p_title, '')), 'A') ||
+           setweight(to_tsvector('english', COALESCE(p_description, '')), 'B') ||
+           setweight(to_tsvector('english', COALESCE(p_seller, '')), 'C');
+$$ LANGUAGE sql IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION update_item_search_vector()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector := item_search_vector(
+        NEW.title, NEW.description,
+        (SELECT username FROM users WHERE user_id = NEW.seller_id));
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_item_search_vector
+BEFORE INSERT OR UPDATE OF title, description, seller_id ON items
+FOR EACH ROW
+EXECUTE FUNCTION update_item_search_vector();
+
+-- Keeps the seller name in the search documents current when a user is renamed.
+CREATE OR REPLACE FUNCTION update_seller_search_vectors()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE items
+       SET search_vector = item_search_vector(title, description, NEW.username)
+     WHERE seller_id = NEW.user_id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_seller_search_vectors
+AFTER UPDATE OF username ON users
+FOR EACH ROW
+WHEN (OLD.username IS DISTINCT FROM NEW.username)
+EXECUTE FUNCTION update_seller_search_vectors();
+
+-- Recomputes the cached reputation of one seller.
+CREATE OR REPLACE FUNCTION refresh_seller_reputation(p_seller_id integer)
+RETURNS void AS $$
+    INSERT INTO seller_reputation (seller_id, average_rating, review_count, positive_percent_12m, completed_sales, updated_at)
+    SELECT p_seller_id,
+           COALESCE(AVG(r.rating), 0),
+           COUNT(r.review_id),
+           100.0 * COUNT(*) FILTER (WHERE r.rating >= 4 AND r.review_date > NOW() - INTERVAL '12 months')
+                 / NULLIF(COUNT(*) FILTER (WHERE r.review_date > NOW() - INTERVAL '12 months'), 0),
+           COUNT(DISTINCT t.transaction_id) FILTER (WHERE EXISTS (
+               SELECT 1 FROM payments p
+               WHERE p.transaction_id = t.transaction_id AND p.payment_status = 'completed'
+           )),
+           NOW()
+      FROM transactions t
+      JOIN auctions a ON t.auction_id = a.auction_id
+      JOIN items i ON a.item_id = i.item_id
+      LEFT JOIN reviews r ON r.transaction_id = t.transaction_id AND r.reviewer_role = 'buyer'
+     WHERE i.seller_id = p_seller_id
+    ON CONFLICT (seller_id) DO UPDATE
+       SET average_rating = EXCLUDED.average_rating,
+           review_count = EXCLUDED.review_count,
+           positive_percent_12m = EXCLUDED.positive_percent_12m,
+           completed_sales = EXCLUDED.completed_sales,
+           updated_at = EXCLUDED.updated_at;
+$$ LANGUAGE sql;
+
+CREATE OR REPLACE FUNCTION update_seller_reputation()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM refresh_seller_reputation(i.seller_id)
+       FROM transactions t
+       JOIN auctions a ON t.auction_id = a.auction_id
+       JOIN items i ON a.item_id = i.item_id
+      WHERE t.transaction_id = NEW.transaction_id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_review_reputation
+AFTER INSERT OR UPDATE OF rating ON reviews
+FOR EACH ROW
+EXECUTE FUNCTION update_seller_reputation();
+
+CREATE TRIGGER trg_sale_reputation
+AFTER INSERT ON transactions
+FOR EACH ROW
+EXECUTE FUNCTION update_seller_reputation();
+
+CREATE TRIGGER trg_payment_reputation
+AFTER UPDATE OF payment_status ON payments
+FOR EACH ROW
+EXECUTE FUNCTION update_seller_reputation();
+
+-- Returns the ledger account of a type, creating it on first use. p_user_id is NULL for the provider and escrow accounts.
+CREATE OR REPLACE FUNCTION ledger_account(p_type varchar, p_user_id integer)
+RETURNS integer AS $$
+DECLARE
+    v_account_id integer;
+BEGIN
+    SELECT account_id INTO v_account_id
+      FROM ledger_accounts
+     WHERE account_type = p_type AND user_id IS NOT DISTINCT FROM p_user_id;
+
+    IF v_account_id IS NULL THEN
+        INSERT INTO ledger_accounts (account_type, user_id)
+        VALUES (p_type, p_user_id)
+        ON CONFLICT DO NOTHING
+        RETURNING account_id INTO v_account_id;
+    END IF;
+
+    -- Another session created the account first
+    IF v_account_id IS NULL THEN
+        SELECT account_id INTO v_account_id
+          FROM ledger_accounts
+         WHERE account_type = p_type AND user_id IS NOT DISTINCT FROM p_user_id;
+    END IF;
+
+    RETURN v_account_id;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Holds a completed payment in escrow, or pays it straight out to the seller if it is below the escrow threshold.
+CREATE OR REPLACE FUNCTION hold_escrow()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.escrowed THEN
+        INSERT INTO ledger_entries (transaction_id, entry_type, from_account_id, to_account_id, amount)
+        VALUES (NEW.transaction_id, 'escrow_hold', ledger_account('provider', NULL), ledger_account('escrow', NULL), NEW.amount)
+        ON CONFLICT (transaction_id, entry_type) DO NOTHING;
+    ELSE
+        INSERT INTO ledger_entries (transaction_id, entry_type, from_account_id, to_account_id, amount)
+        SELECT NEW.transaction_id, 'payout', ledger_account('provider', NULL), ledger_account('seller', i.seller_id), NEW.amount
+          FROM transactions t
+          JOIN auctions a ON t.auction_id = a.auction_id
+          JOIN items i ON a.item_id = i.item_id
+         WHERE t.transaction_id = NEW.transaction_id
+        ON CONFLICT (transaction_id, entry_type) DO NOTHING;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_payment_escrow
+AFTER UPDATE OF payment_status ON payments
+FOR EACH ROW
+WHEN (NEW.payment_status = 'completed' AND OLD.payment_status <> 'completed')
+EXECUTE FUNCTION hold_escrow();
+
+-- Releases what escrow holds for a transaction to the seller once its delivery is confirmed, by the buyer or automatically.
+CREATE OR REPLACE FUNCTION release_escrow()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO ledger_entries (transaction_id, entry_type, from_account_id, to_account_id, amount)
+    SELECT h.transaction_id, 'escrow_release', h.to_account_id, ledger_account('seller', i.seller_id), h.amount
+      FROM ledger_entries h
+      JOIN transactions t ON h.transaction_id = t.transaction_id
+      JOIN auctions a ON t.auction_id = a.auction_id
+      JOIN items i ON a.item_id = i.item_id
+     WHERE h.transaction_id = NEW.transaction_id AND h.entry_type = 'escrow_hold'
+    ON CONFLICT (transaction_id, entry_type) DO NOTHING;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_delivery_escrow
+AFTER UPDATE OF delivery_status ON deliveries
+FOR EACH ROW
+WHEN (NEW.delivery_status = 'delivered' AND OLD.delivery_status <> 'delivered')
+EXECUTE FUNCTION release_escrow();
+
+-- Refunds a payment by moving it back to the provider from escrow, or from the seller if it was paid out. Escrow that was already released is not refunded.
+CREATE OR REPLACE FUNCTION refund_payment()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO ledger_entries (transaction_id, entry_type, from_account_id, to_account_id, amount)
+    SELECT e.transaction_id,
+           CASE e.entry_type WHEN 'escrow_hold' THEN 'escrow_refund' ELSE 'payout_refund' END,
+           e.to_account_id, e.from_account_id, e.amount
+      FROM ledger_entries e
+     WHERE e.transaction_id = NEW.transaction_id
+       AND e.entry_type IN ('escrow_hold', 'payout')
+       AND NOT EXISTS (
+           SELECT 1 FROM ledger_entries r
+           WHERE r.transaction_id = e.transaction_id AND r.entry_type = 'escrow_release'
+       )
+    ON CONFLICT (transaction_id, entry_type) DO NOTHING;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_payment_refund
+AFTER UPDATE OF payment_status ON payments
+FOR EACH ROW
+WHEN (NEW.payment_status = 'refunded' AND OLD.payment_status = 'completed')
+EXECUTE FUNCTION refund_payment();
+
 -- Procedure to change the status of payments once payment is completed.
 CREATE PROCEDURE finalize_transaction(p_transaction_id integer)
 LANGUAGE plpgsql
 AS $$
 
 BEGIN
-    -- Update the payment record to mark the payment as completed, only if it is still pending.
+    -- Update the payment record to mark the payment as completed, only if it is still pending and not overdue.
     UPDATE payments 
-       SET payment_status = 'completed'
+       SET payment_status = 'completed', payment_date = CURRENT_TIMESTAMP
      WHERE transaction_id = p_transaction_id
-       AND payment_status = 'pending';
+       AND payment_status = 'pending'
+       AND due_at > NOW();
        
     --RAISE NOTICE 'Transaction % finalized; payment status updated to completed', p_transaction_id;
 END;
@@ -204,10 +640,14 @@ $$;
 -- Items: Focus on seller activity and status filtering
 CREATE INDEX IF NOT EXISTS idx_items_seller ON items(seller_id); -- Replaces individual seller_id/status indexes
 CREATE INDEX IF NOT EXISTS idx_items_highest_bidder ON items(current_highest_bidder);
+CREATE INDEX IF NOT EXISTS idx_items_category ON items(category_id);
+CREATE INDEX IF NOT EXISTS idx_item_tags_tag ON item_tags(tag);
+CREATE INDEX IF NOT EXISTS idx_items_search ON items USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_categories_parent ON categories(parent_id);
 
 -- Auctions: Optimize time-based queries and status checks
 CREATE INDEX IF NOT EXISTS idx_auctions_time_status ON auctions(auction_status, end_time);
-CREATE INDEX IF NOT EXISTS idx_auctions_item ON auctions(item_id); -- Already exists via UNIQUE
+CREATE INDEX IF NOT EXISTS idx_auctions_item ON auctions(item_id); -- An item has one auction per listing
 
 -- Bids: Speed up bid analysis and user activity lookups
 CREATE INDEX IF NOT EXISTS idx_bids_auction_amount ON bids(auction_id, bid_amount DESC); -- For finding highest bids
@@ -218,16 +658,39 @@ CREATE INDEX IF NOT EXISTS idx_automated_bids_auction_user ON automated_bids(auc
 
 -- Auction Participants: Support user-centric queries
 CREATE INDEX IF NOT EXISTS idx_participants_user ON auction_participants(user_id);
+CREATE INDEX IF NOT EXISTS idx_watchlist_auction ON watchlist(auction_id);
+CREATE INDEX IF NOT EXISTS idx_saved_searches_user ON saved_searches(user_id);
+CREATE INDEX IF NOT EXISTS idx_saved_search_matches_pending ON saved_search_matches(search_id) WHERE notified_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_notifications_user ON notifications(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_seller_follows_seller ON seller_follows(seller_id);
 
 -- Transactions: Accelerate history lookups and joins
-CREATE INDEX IF NOT EXISTS idx_transactions_buyer_date ON transactions(transaction_date);
+CREATE INDEX IF NOT EXISTS idx_transactions_buyer_date ON transactions(buyer_id, transaction_date);
+
+-- Second-chance offers: A buyer's open offers
+CREATE INDEX IF NOT EXISTS idx_second_chance_buyer_status ON second_chance_offers(buyer_id, offer_status);
 
 -- Admin Log: Audit trail optimization
 CREATE INDEX IF NOT EXISTS idx_admin_log_table_deleted ON admin_delete_log(changed_at);
 CREATE INDEX IF NOT EXISTS idx_admin_log_table_updated ON admin_update_log(changed_at);
 
+-- Payments: Pending payments by deadline
+CREATE INDEX IF NOT EXISTS idx_payments_status_due ON payments(payment_status, due_at);
+
+-- Deliveries: Shipments waiting to be confirmed
+CREATE INDEX IF NOT EXISTS idx_deliveries_status_shipped ON deliveries(delivery_status, shipped_at);
+
+-- Ledger: One provider and one escrow account, one account per seller, and entries by account
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ledger_accounts_system ON ledger_accounts(account_type) WHERE user_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ledger_accounts_seller ON ledger_accounts(user_id) WHERE user_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_from ON ledger_entries(from_account_id);
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_to ON ledger_entries(to_account_id);
+
+-- Strikes: Count a buyer's strikes
+CREATE INDEX IF NOT EXISTS idx_buyer_strikes_user ON buyer_strikes(user_id);
+
 -- Reviews: Enable user reputation checks
-CREATE INDEX IF NOT EXISTS idx_reviews_reviewee ON reviews(transaction_id);
+CREATE INDEX IF NOT EXISTS idx_reviews_reviewee ON reviews(reviewee_id, review_date);
 
 -- Procedure to close an auction
 CREATE PROCEDURE close_auction(p_auction_id integer)
@@ -261,8 +724,8 @@ BEGIN
     
     IF v_highest_bid IS NOT NULL THEN
         -- Insert only auction_id; transaction_id auto-increments, transaction_date defaults
-        INSERT INTO transactions(auction_id) 
-        VALUES (p_auction_id);
+        INSERT INTO transactions(auction_id, buyer_id, amount) 
+        VALUES (p_auction_id, v_highest_bidder, v_highest_bid);
     END IF;
     
     -- Call the notification procedure (assumes it’s defined to accept auction and item IDs)
@@ -23,11 +23,15 @@ const BoughtItems = () => {
   };
 
   const handleStarClick = (auctionId, starValue) => {
+    const comment = window.prompt("Tell others about your experience with this seller");
+    if (!comment || !comment.trim()) {
+      return;
+    }
     setRating(prev => ({
       ...prev,
       [auctionId]: starValue
     }));
-    submitReview(auctionId, starValue);
+    submitReview(auctionId, starValue, comment.trim());
   };
 
   if (loading && boughtItems.length === 0) {
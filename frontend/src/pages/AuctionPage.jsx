@@ -29,6 +29,13 @@ const AuctionPage = () => {
         if (message.type === 'new_bid' && message.data.auction_id === parseInt(auction_id)) {
           fetchAuction(auction_id);
         }
+        if (message.type === 'auction_extended' && message.data.auction_id === parseInt(auction_id)) {
+          toast("Late bid received, the auction has been extended!");
+          fetchAuction(auction_id);
+        }
+        if (message.type === 'price_drop' && message.data.auction_id === parseInt(auction_id)) {
+          fetchAuction(auction_id);
+        }
         if (message.type === 'auction_status' && message.data.auction_id === parseInt(auction_id)) {
           const newStatus = message.data.status;
           const notification = newStatus === 'closed' 
@@ -213,9 +220,9 @@ const AuctionPage = () => {
                     </label>
                     <input
                       type="number"
-                      step="1"
-                      min={currentAuction.current_highest_bid > 0 ? currentAuction.current_highest_bid + 1 : currentAuction.starting_bid + 1}
-                      placeholder="Enter your bid amount"
+                      step="0.01"
+                      min={currentAuction.next_min_bid}
+                      placeholder={`Enter ${currentAuction.next_min_bid?.toFixed(2)} or more`}
                       className="input input-bordered w-full"
                       value={bidAmount}
                       onChange={(e) => setBidAmount(e.target.value)}
@@ -241,8 +248,8 @@ const AuctionPage = () => {
                     </label>
                     <input
                       type="number"
-                      step="1"
-                      min={currentAuction.highest_bid > 0 ? currentAuction.highest_bid + 1 : currentAuction.starting_bid}
+                      step="0.01"
+                      min={currentAuction.next_min_bid}
                       placeholder="Enter your maximum bid amount"
                       className="input input-bordered w-full"
                       value={automatedBidAmount}
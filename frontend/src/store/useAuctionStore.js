@@ -15,7 +15,7 @@ export const useAuctionStore = create((set, get) => ({
     set({ loading: true, error: null });
     try {
       const response = await axiosInstance.get("/api/auctions");
-      const auctions = Array.isArray(response.data) ? response.data : [];
+      const auctions = Array.isArray(response.data?.items) ? response.data.items : [];
       set({ auctions, loading: false });
     } catch (err) {
       const errorMsg =
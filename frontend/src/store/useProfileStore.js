@@ -45,7 +45,7 @@ export const useProfileStore = create((set, get) => ({
     set({ loading: true, error: null });
     try {
       const response = await axiosInstance.get("/api/profile/bids");
-      set({ biddingHistory: response.data, loading: false });
+      set({ biddingHistory: response.data.items, loading: false });
     } catch (err) {
       const errorMsg = err.response?.data?.error || err.message;
       console.error("fetchBiddingHistory error:", err);
@@ -59,7 +59,7 @@ export const useProfileStore = create((set, get) => ({
     set({ loading: true, error: null });
     try {
       const response = await axiosInstance.get("/api/profile/sold");
-      set({ soldItems: response.data, loading: false });
+      set({ soldItems: response.data.items, loading: false });
     } catch (err) {
       const errorMsg = err.response?.data?.error || err.message;
       console.error("fetchSoldItems error:", err);
@@ -72,7 +72,7 @@ export const useProfileStore = create((set, get) => ({
     set({ loading: true, error: null });
     try {
       const response = await axiosInstance.get("/api/profile/bought");
-      set({ boughtItems: response.data, loading: false });
+      set({ boughtItems: response.data.items, loading: false });
     } catch (err) {
       const errorMsg = err.response?.data?.error || err.message;
       console.error("fetchBoughtItems error:", err);
@@ -81,10 +81,10 @@ export const useProfileStore = create((set, get) => ({
     }
   },
 
-  submitReview: async (auction_id, star_value) => {
+  submitReview: async (auction_id, star_value, comment) => {
     set({ loading: true, error: null });
     try {
-      const response = await axiosInstance.post("/api/reviews", {auction_id: auction_id, rating: star_value})
+      const response = await axiosInstance.post("/api/reviews", {auction_id: auction_id, rating: star_value, comment: comment})
       set({ loading: false });
       toast.success("Review submitted successfully!");
       await get().fetchBoughtItems();